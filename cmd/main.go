@@ -4,33 +4,216 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"log/slog"
+	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/infinityCounter2/vh-trader/internal/auth"
+	"github.com/infinityCounter2/vh-trader/internal/config"
+	"github.com/infinityCounter2/vh-trader/internal/logic"
 	"github.com/infinityCounter2/vh-trader/internal/server"
 )
 
-var port int
+var (
+	configFile  string
+	printConfig bool
+	genAPIKey   bool
+	flags       *config.Flags
+)
 
 func init() {
-	flag.IntVar(&port, "port", 9001, "The default port the server should run on")
+	flag.StringVar(&configFile, "config", os.Getenv("VH_CONFIG"), "Path to a YAML config file, defaults to $VH_CONFIG")
+	flag.BoolVar(&printConfig, "print-config", false, "Print the effective config as YAML and exit")
+	flag.BoolVar(&genAPIKey, "gen-api-key", false, "Print a new API key and its hash for auth.keys and exit")
+	flags = config.RegisterFlags(flag.CommandLine)
 }
 
 func main() {
 	flag.Parse()
 
+	if genAPIKey {
+		key := auth.GenerateKey()
+		fmt.Printf("key:  %s\nhash: %s\n", key, auth.HashKey(key))
+		return
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config:\n%s\n", err)
+		os.Exit(1)
+	}
+
+	if printConfig {
+		out, err := cfg.YAML()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to print config: %s\n", err)
+			os.Exit(1)
+		}
+		os.Stdout.Write(out)
+		return
+	}
+
+	// Validated above, so these can't fail.
+	level, _ := cfg.LogLevel()
+	intervals, _ := cfg.Intervals()
+	normalizer, err := cfg.Normalizer()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid symbols.aliases_file: %s\n", err)
+		os.Exit(1)
+	}
+	bars, _ := cfg.Bars(normalizer)
+	symbolCacheLimits, _ := cfg.SymbolCacheLimits(normalizer)
+	keyring, _ := cfg.Keyring()
+	verifier, _ := cfg.Verifier()
+
+	var tlsParams *server.TLSParams
+	if cfg.TLS.CertFile != "" {
+		minVersion, _ := cfg.TLSMinVersion()
+		cipherSuites, _ := cfg.TLSCipherSuites()
+		tlsParams = &server.TLSParams{
+			CertFile:     cfg.TLS.CertFile,
+			KeyFile:      cfg.TLS.KeyFile,
+			MinVersion:   minVersion,
+			CipherSuites: cipherSuites,
+			ClientCerts:  cfg.ClientCerts(),
+			ClientCAFile: cfg.TLS.ClientCAFile,
+		}
+	}
+
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(level)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel}))
+	slog.SetDefault(logger)
+
+	symbols, err := loadSymbols(cfg, normalizer)
+	if err != nil {
+		logger.Error("Invalid symbols.file", slog.Any("error", err))
+		os.Exit(1)
+	}
+
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
 	httpServer := server.NewServer(server.Params{
-		Port: port,
+		Port:              cfg.Server.Port,
+		TLS:               tlsParams,
+		CORS:              corsParams(cfg),
+		ReadTimeout:       time.Duration(cfg.Server.ReadTimeout),
+		WriteTimeout:      time.Duration(cfg.Server.WriteTimeout),
+		IdleTimeout:       time.Duration(cfg.Server.IdleTimeout),
+		ShutdownTimeout:   time.Duration(cfg.Server.ShutdownTimeout),
+		ShutdownDrain:     time.Duration(cfg.Server.ShutdownDrain),
+		Limits:            limits(cfg),
+		CacheLimit:        cfg.Cache.Limit,
+		SymbolCacheLimits: symbolCacheLimits,
+		Symbols:           symbols,
+		Normalizer:        normalizer,
+		Intervals:         intervals,
+		LatePolicy:        cfg.LatePolicy(),
+		AllowedLateness:   time.Duration(cfg.Candles.AllowedLateness),
+		Bars:              bars,
+		APIKeys:           keyring,
+		Signing:           verifier,
+		Reload: func() (server.Reloadable, error) {
+			next, err := loadConfig()
+			if err != nil {
+				return server.Reloadable{}, err
+			}
+			if cfg.RequiresRestart(next) {
+				logger.Warn("Only cache, symbols, auth, limits, log and cors settings are reloaded, the others require a restart")
+			}
+
+			// The aliases require a restart, so the symbols are
+			// normalized like those of the running server.
+			symbols, err := loadSymbols(next, normalizer)
+			if err != nil {
+				return server.Reloadable{}, fmt.Errorf("symbols.file: %w", err)
+			}
+			symbolCacheLimits, err := next.SymbolCacheLimits(normalizer)
+			if err != nil {
+				return server.Reloadable{}, fmt.Errorf("cache.symbol_limits: %w", err)
+			}
+			keyring, _ := next.Keyring()
+			verifier, _ := next.Verifier()
+			level, _ := next.LogLevel()
+			return server.Reloadable{
+				Symbols:           symbols,
+				CacheLimit:        next.Cache.Limit,
+				SymbolCacheLimits: symbolCacheLimits,
+				APIKeys:           keyring,
+				Signing:           verifier,
+				Limits:            limits(next),
+				LogLevel:          level,
+				CORS:              corsParams(next),
+			}, nil
+		},
+		Logger:   logger,
+		LogLevel: logLevel,
 	})
 
-	fmt.Printf("Starting server on port :%d\n", port)
+	// SIGHUP reloads the config, as does POST /admin/reload.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			httpServer.Reload()
+		}
+	}()
+
+	logger.Info("Starting server", slog.Int("port", cfg.Server.Port), slog.Bool("tls", tlsParams != nil))
 
 	if err := httpServer.Run(ctx); err != nil {
-		fmt.Printf("Server Run Error: %s\n", err)
+		logger.Error("Server Run Error", slog.Any("error", err))
+	}
+
+	logger.Info("Server shutdown gracefully")
+}
+
+// loadConfig loads the config from the file, environment and flags.
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load(configFile, os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	if err := flags.Apply(cfg); err != nil {
+		return nil, err
 	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
 
-	fmt.Println("Server shutdown gracefully")
+// loadSymbols loads the symbol registry of the config with its symbols
+// normalized, returning nil when the config has no symbols file.
+func loadSymbols(cfg *config.Config, normalizer *logic.SymbolNormalizer) (*logic.SymbolRegistry, error) {
+	if cfg.Symbols.File == "" {
+		return nil, nil
+	}
+	return logic.LoadSymbolRegistry(cfg.Symbols.File, normalizer)
+}
+
+// limits returns the server limits of the config.
+func limits(cfg *config.Config) server.Limits {
+	return server.Limits{
+		MaxBodyBytes:      cfg.Limits.MaxBodyBytes,
+		MaxTradesPerBatch: cfg.Limits.MaxTradesPerBatch,
+		IngestRate:        cfg.Limits.IngestRate,
+		IngestBurst:       cfg.Limits.IngestBurst,
+		ReadRate:          cfg.Limits.ReadRate,
+		ReadBurst:         cfg.Limits.ReadBurst,
+	}
+}
+
+// corsParams returns the server CORS params of the config.
+func corsParams(cfg *config.Config) server.CORSParams {
+	return server.CORSParams{
+		AllowedOrigins: cfg.CORS.AllowedOrigins,
+		AllowedMethods: cfg.CORS.AllowedMethods,
+		AllowedHeaders: cfg.CORS.AllowedHeaders,
+		ExposedHeaders: cfg.CORS.ExposedHeaders,
+		MaxAge:         time.Duration(cfg.CORS.MaxAge),
+	}
 }
@@ -0,0 +1,170 @@
+// Package auth implements API keys with roles, request signing and
+// the policy for client certificates.
+//
+// Only the SHA-256 hashes of keys are stored, keys being random enough
+// that they don't need a salt or a slow hash to resist guessing.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Role grants access to a group of endpoints.
+type Role string
+
+const (
+	// RoleRead grants reading trades, candles and the like.
+	RoleRead Role = "read"
+	// RoleIngest grants ingesting, cancelling and correcting trades.
+	RoleIngest Role = "ingest"
+	// RoleAdmin grants the admin endpoints, and every other role.
+	RoleAdmin Role = "admin"
+)
+
+// ParseRole returns the Role of the given name,
+// and false if there is no such role.
+func ParseRole(name string) (Role, bool) {
+	switch r := Role(name); r {
+	case RoleRead, RoleIngest, RoleAdmin:
+		return r, true
+	}
+	return "", false
+}
+
+// Hash is the SHA-256 hash of an API key.
+type Hash [sha256.Size]byte
+
+// HashKey returns the hash of the key.
+func HashKey(key string) Hash {
+	return sha256.Sum256([]byte(key))
+}
+
+// ParseHash parses a hash written as hex.
+func ParseHash(s string) (Hash, error) {
+	var h Hash
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != len(h) {
+		return h, fmt.Errorf("hash must be %d hex characters", 2*len(h))
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// GenerateKey returns a new random API key.
+func GenerateKey() string {
+	var b [32]byte
+	rand.Read(b[:])
+	return base64.RawURLEncoding.EncodeToString(b[:])
+}
+
+// Key is an API key known by its hash.
+type Key struct {
+	// Name identifies the key in logs.
+	Name  string
+	Hash  Hash
+	Roles []Role
+}
+
+// Has reports whether the key was granted the role.
+func (k Key) Has(role Role) bool {
+	return slices.Contains(k.Roles, role) || slices.Contains(k.Roles, RoleAdmin)
+}
+
+// Keyring holds the API keys that are accepted.
+type Keyring struct {
+	// Keyed by Hash
+	keys map[Hash]Key
+}
+
+// NewKeyring creates a keyring of the given keys, returning an error if
+// any of them are invalid or share their name or hash with another.
+func NewKeyring(keys []Key) (*Keyring, error) {
+	k := &Keyring{keys: make(map[Hash]Key, len(keys))}
+
+	names := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		if key.Name == "" {
+			return nil, errors.New("key name is required")
+		}
+		if len(key.Roles) == 0 {
+			return nil, fmt.Errorf("key %s has no roles", key.Name)
+		}
+		if _, exists := names[key.Name]; exists {
+			return nil, fmt.Errorf("key %s is defined more than once", key.Name)
+		}
+		if _, exists := k.keys[key.Hash]; exists {
+			return nil, fmt.Errorf("key %s has the same hash as another key", key.Name)
+		}
+
+		names[key.Name] = struct{}{}
+		key.Roles = slices.Clone(key.Roles)
+		k.keys[key.Hash] = key
+	}
+	return k, nil
+}
+
+// Authenticate returns the key matching the given API key.
+func (k *Keyring) Authenticate(key string) (Key, bool) {
+	found, ok := k.keys[HashKey(key)]
+	return found, ok
+}
+
+// Keys returns every key sorted by name.
+func (k *Keyring) Keys() []Key {
+	keys := make([]Key, 0, len(k.keys))
+	for _, key := range k.keys {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Name < keys[j].Name
+	})
+	return keys
+}
+
+// ClientCerts is the policy for the certificates TLS clients present.
+type ClientCerts string
+
+const (
+	// ClientCertsNone doesn't ask clients for certificates.
+	ClientCertsNone ClientCerts = "none"
+	// ClientCertsIngest verifies the certificates clients present,
+	// and requires one on the endpoints of the ingest role.
+	ClientCertsIngest ClientCerts = "ingest"
+	// ClientCertsAll requires a verified certificate on every connection.
+	ClientCertsAll ClientCerts = "all"
+)
+
+// ParseClientCerts returns the ClientCerts policy of the given
+// name, and false if there is no such policy.
+func ParseClientCerts(name string) (ClientCerts, bool) {
+	switch c := ClientCerts(name); c {
+	case ClientCertsNone, ClientCertsIngest, ClientCertsAll:
+		return c, true
+	}
+	return "", false
+}
+
+// TLSClientAuth returns the tls.ClientAuthType enforcing the policy
+// during the handshake, the ingest policy being enforced per request.
+func (c ClientCerts) TLSClientAuth() tls.ClientAuthType {
+	switch c {
+	case ClientCertsIngest:
+		return tls.VerifyClientCertIfGiven
+	case ClientCertsAll:
+		return tls.RequireAndVerifyClientCert
+	}
+	return tls.NoClientCert
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring(t *testing.T) {
+	feedKey, adminKey := GenerateKey(), GenerateKey()
+	require.NotEqual(t, feedKey, adminKey, "Expected random keys")
+
+	keyring, err := NewKeyring([]Key{
+		{Name: "feed", Hash: HashKey(feedKey), Roles: []Role{RoleIngest}},
+		{Name: "ops", Hash: HashKey(adminKey), Roles: []Role{RoleAdmin}},
+	})
+	require.NoError(t, err, "Failed to create keyring")
+
+	feed, ok := keyring.Authenticate(feedKey)
+	require.True(t, ok, "Expected the feed key to authenticate")
+	require.Equal(t, "feed", feed.Name)
+	require.True(t, feed.Has(RoleIngest), "Expected the feed key to ingest")
+	require.False(t, feed.Has(RoleRead), "Expected the feed key not to read")
+
+	ops, ok := keyring.Authenticate(adminKey)
+	require.True(t, ok, "Expected the admin key to authenticate")
+	require.True(t, ops.Has(RoleRead), "Expected admin to grant every role")
+
+	_, ok = keyring.Authenticate("guess")
+	require.False(t, ok, "Expected unknown keys to fail")
+
+	require.Equal(t, []string{"feed", "ops"}, []string{keyring.Keys()[0].Name, keyring.Keys()[1].Name},
+		"Expected keys sorted by name")
+}
+
+func TestNewKeyring_Invalid(t *testing.T) {
+	hash := HashKey("key")
+
+	_, err := NewKeyring([]Key{{Hash: hash, Roles: []Role{RoleRead}}})
+	require.Error(t, err, "Expected a missing name to fail")
+
+	_, err = NewKeyring([]Key{{Name: "a", Hash: hash}})
+	require.Error(t, err, "Expected a key without roles to fail")
+
+	_, err = NewKeyring([]Key{
+		{Name: "a", Hash: hash, Roles: []Role{RoleRead}},
+		{Name: "b", Hash: hash, Roles: []Role{RoleRead}},
+	})
+	require.Error(t, err, "Expected a duplicate hash to fail")
+
+	_, err = NewKeyring([]Key{
+		{Name: "a", Hash: hash, Roles: []Role{RoleRead}},
+		{Name: "a", Hash: HashKey("other"), Roles: []Role{RoleRead}},
+	})
+	require.Error(t, err, "Expected a duplicate name to fail")
+}
+
+func TestParseHash(t *testing.T) {
+	hash := HashKey("key")
+	parsed, err := ParseHash(hash.String())
+	require.NoError(t, err, "Failed to parse hash")
+	require.Equal(t, hash, parsed, "Expected the hash to round trip")
+
+	_, err = ParseHash("abc")
+	require.Error(t, err, "Expected a short hash to fail")
+
+	_, err = ParseHash(hash.String()[:62] + "zz")
+	require.Error(t, err, "Expected non hex characters to fail")
+}
+
+func TestParseClientCerts(t *testing.T) {
+	certs, ok := ParseClientCerts("ingest")
+	require.True(t, ok, "Expected ingest to parse")
+	require.Equal(t, tls.VerifyClientCertIfGiven, certs.TLSClientAuth(),
+		"Expected certificates to be optional during the handshake")
+
+	certs, ok = ParseClientCerts("all")
+	require.True(t, ok, "Expected all to parse")
+	require.Equal(t, tls.RequireAndVerifyClientCert, certs.TLSClientAuth())
+
+	_, ok = ParseClientCerts("some")
+	require.False(t, ok, "Expected unknown policies to fail")
+}
@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The headers of a signed request.
+const (
+	// SignatureHeader is the hex HMAC-SHA256 of the request.
+	SignatureHeader = "X-Signature"
+	// SignatureKeyHeader is the ID of the secret it was signed with.
+	SignatureKeyHeader = "X-Signature-Key"
+	// SignatureTimestampHeader is the time(ms) it was signed at.
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+	// SignatureNonceHeader is unique to the request so it can't be replayed.
+	SignatureNonceHeader = "X-Signature-Nonce"
+)
+
+// maxNonceLen bounds the nonces kept in the NonceCache.
+const maxNonceLen = 128
+
+var (
+	ErrSignatureMissing  = errors.New("request is not signed")
+	ErrSignatureInvalid  = errors.New("signature is invalid")
+	ErrSignatureExpired  = errors.New("signature timestamp is outside the allowed skew")
+	ErrSignatureReplayed = errors.New("signature nonce was already used")
+)
+
+// Sign returns the signature of a request, the hex HMAC-SHA256 of its
+// method, path, timestamp(ms), nonce and body separated by newlines.
+func Sign(secret []byte, method, path string, timestamp int64, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%s\n%s\n%d\n%s\n", method, path, timestamp, nonce)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verifier verifies the signatures of requests.
+type Verifier struct {
+	// Keyed by secret ID
+	secrets map[string][]byte
+	maxSkew time.Duration
+}
+
+// NewVerifier creates a verifier of requests signed with any of the
+// secrets, keyed by their ID, at most maxSkew away from the server clock.
+func NewVerifier(secrets map[string]string, maxSkew time.Duration) (*Verifier, error) {
+	if maxSkew <= 0 {
+		return nil, errors.New("max skew must be positive")
+	}
+
+	v := &Verifier{secrets: make(map[string][]byte, len(secrets)), maxSkew: maxSkew}
+	for id, secret := range secrets {
+		if id == "" {
+			return nil, errors.New("secret id is required")
+		}
+		if len(secret) < 32 {
+			return nil, fmt.Errorf("secret %s must be at least 32 characters", id)
+		}
+		v.secrets[id] = []byte(secret)
+	}
+	return v, nil
+}
+
+// Verify checks the signature of the request and that it was signed
+// within the max skew of now, returning the ID of the secret and the
+// nonce the request was signed with.
+//
+// The nonce must then be checked against a NonceCache, which is left
+// to the caller so the cache outlives reloads of the secrets.
+func (v *Verifier) Verify(r *http.Request, body []byte, now time.Time) (id, nonce string, err error) {
+	signature := r.Header.Get(SignatureHeader)
+	id = r.Header.Get(SignatureKeyHeader)
+	nonce = r.Header.Get(SignatureNonceHeader)
+	if signature == "" || id == "" || nonce == "" || r.Header.Get(SignatureTimestampHeader) == "" {
+		return "", "", ErrSignatureMissing
+	}
+	if len(nonce) > maxNonceLen {
+		return "", "", ErrSignatureInvalid
+	}
+
+	timestamp, err := strconv.ParseInt(r.Header.Get(SignatureTimestampHeader), 10, 64)
+	if err != nil {
+		return "", "", ErrSignatureInvalid
+	}
+
+	secret, ok := v.secrets[id]
+	if !ok {
+		return "", "", ErrSignatureInvalid
+	}
+	expected := Sign(secret, r.Method, r.URL.Path, timestamp, nonce, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return "", "", ErrSignatureInvalid
+	}
+
+	// Checked after the signature so that unsigned
+	// requests can't probe the server clock.
+	if skew := now.Sub(time.UnixMilli(timestamp)).Abs(); skew > v.maxSkew {
+		return "", "", ErrSignatureExpired
+	}
+	return id, nonce, nil
+}
+
+// MaxSkew returns how far from the server clock signatures may be.
+func (v *Verifier) MaxSkew() time.Duration {
+	return v.maxSkew
+}
+
+// IDs returns the IDs of the secrets sorted.
+func (v *Verifier) IDs() []string {
+	ids := make([]string, 0, len(v.secrets))
+	for id := range v.secrets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Equal reports whether the secret of the ID is the same in both verifiers.
+func (v *Verifier) Equal(o *Verifier, id string) bool {
+	return hmac.Equal(v.secrets[id], o.secrets[id])
+}
+
+// NonceCache remembers the nonces of verified requests until their
+// signature expires, to reject requests being replayed.
+type NonceCache struct {
+	mtx sync.Mutex
+	// expiries is keyed by nonce and contains when it can be forgotten.
+	expiries map[string]time.Time
+	// order holds the nonces in the order they expire in.
+	order []string
+}
+
+func NewNonceCache() *NonceCache {
+	return &NonceCache{expiries: make(map[string]time.Time)}
+}
+
+// Use records the nonce as used until ttl after now, returning false
+// if it was already used. Nonces are scoped to the ID of the secret.
+//
+// The ttl must be the same for every call, twice the max skew, so
+// that nonces expire in the order they were used.
+func (c *NonceCache) Use(id, nonce string, now time.Time, ttl time.Duration) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	// Forget the nonces whose signatures can no longer be verified.
+	expired := 0
+	for _, key := range c.order {
+		if c.expiries[key].After(now) {
+			break
+		}
+		delete(c.expiries, key)
+		expired++
+	}
+	c.order = c.order[expired:]
+
+	key := id + "\x00" + nonce
+	if _, used := c.expiries[key]; used {
+		return false
+	}
+	c.expiries[key] = now.Add(ttl)
+	c.order = append(c.order, key)
+	return true
+}
+
+// Used reports whether the nonce is recorded as used at now, without
+// recording it, to reject replays before they cost anything more.
+func (c *NonceCache) Used(id, nonce string, now time.Time) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	expiry, used := c.expiries[id+"\x00"+nonce]
+	return used && expiry.After(now)
+}
+
+// Len returns the number of nonces remembered.
+func (c *NonceCache) Len() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	return len(c.expiries)
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func signedRequest(secret string, ts time.Time, nonce, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/ingest", strings.NewReader(body))
+	r.Header.Set(SignatureKeyHeader, "feed")
+	r.Header.Set(SignatureTimestampHeader, strconv.FormatInt(ts.UnixMilli(), 10))
+	r.Header.Set(SignatureNonceHeader, nonce)
+	r.Header.Set(SignatureHeader, Sign([]byte(secret), http.MethodPost, "/ingest", ts.UnixMilli(), nonce, []byte(body)))
+	return r
+}
+
+func TestVerifier(t *testing.T) {
+	secret := GenerateKey()
+	v, err := NewVerifier(map[string]string{"feed": secret}, 30*time.Second)
+	require.NoError(t, err, "Failed to create verifier")
+
+	now := time.UnixMilli(1_700_000_000_000)
+	body := `[{"trade_id":"1"}]`
+
+	id, nonce, err := v.Verify(signedRequest(secret, now.Add(-10*time.Second), "n1", body), []byte(body), now)
+	require.NoError(t, err, "Expected a signed request to verify")
+	require.Equal(t, "feed", id)
+	require.Equal(t, "n1", nonce)
+
+	_, _, err = v.Verify(signedRequest(secret, now, "n1", body), []byte(`[]`), now)
+	require.ErrorIs(t, err, ErrSignatureInvalid, "Expected a tampered body to fail")
+
+	_, _, err = v.Verify(signedRequest(GenerateKey(), now, "n1", body), []byte(body), now)
+	require.ErrorIs(t, err, ErrSignatureInvalid, "Expected another secret to fail")
+
+	r := signedRequest(secret, now, "n1", body)
+	r.URL.Path = "/trades/cancel"
+	_, _, err = v.Verify(r, []byte(body), now)
+	require.ErrorIs(t, err, ErrSignatureInvalid, "Expected another path to fail")
+
+	_, _, err = v.Verify(signedRequest(secret, now.Add(-time.Minute), "n1", body), []byte(body), now)
+	require.ErrorIs(t, err, ErrSignatureExpired, "Expected an old timestamp to fail")
+
+	_, _, err = v.Verify(signedRequest(secret, now.Add(time.Minute), "n1", body), []byte(body), now)
+	require.ErrorIs(t, err, ErrSignatureExpired, "Expected a future timestamp to fail")
+
+	r = signedRequest(secret, now, "n1", body)
+	r.Header.Del(SignatureHeader)
+	_, _, err = v.Verify(r, []byte(body), now)
+	require.ErrorIs(t, err, ErrSignatureMissing, "Expected an unsigned request to fail")
+}
+
+func TestNewVerifier_Invalid(t *testing.T) {
+	_, err := NewVerifier(map[string]string{"feed": "short"}, time.Second)
+	require.Error(t, err, "Expected a short secret to fail")
+
+	_, err = NewVerifier(map[string]string{"": GenerateKey()}, time.Second)
+	require.Error(t, err, "Expected a missing id to fail")
+
+	_, err = NewVerifier(nil, 0)
+	require.Error(t, err, "Expected a zero skew to fail")
+}
+
+func TestNonceCache(t *testing.T) {
+	c := NewNonceCache()
+	now := time.UnixMilli(1_700_000_000_000)
+	ttl := time.Minute
+
+	require.False(t, c.Used("feed", "n1", now), "Expected a new nonce not to be used")
+	require.True(t, c.Use("feed", "n1", now, ttl), "Expected a new nonce to be accepted")
+	require.True(t, c.Used("feed", "n1", now), "Expected an accepted nonce to be used")
+	require.False(t, c.Used("feed", "n1", now.Add(ttl)), "Expected an expired nonce not to be used")
+	require.False(t, c.Use("feed", "n1", now.Add(time.Second), ttl), "Expected a replayed nonce to fail")
+	require.True(t, c.Use("other", "n1", now, ttl), "Expected nonces to be scoped to the secret")
+
+	require.True(t, c.Use("feed", "n2", now.Add(30*time.Second), ttl))
+	require.True(t, c.Use("feed", "n3", now.Add(ttl), ttl), "Expected a new nonce to be accepted")
+	require.Equal(t, 2, c.Len(), "Expected the expired nonces to be forgotten")
+	require.False(t, c.Use("feed", "n2", now.Add(ttl), ttl), "Expected unexpired nonces to be kept")
+}
@@ -0,0 +1,589 @@
+// Package config loads the configuration of the server.
+//
+// Settings are layered, each overriding the previous one: the defaults,
+// an optional YAML file, VH_ prefixed environment variables and finally
+// the command line flags.
+package config
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/infinityCounter2/vh-trader/internal/auth"
+	"github.com/infinityCounter2/vh-trader/internal/logic"
+	"github.com/infinityCounter2/vh-trader/internal/models"
+)
+
+// EnvPrefix prefixes the environment variables overriding settings, which
+// are named after the path of the setting, e.g. VH_SERVER_PORT.
+const EnvPrefix = "VH"
+
+type Config struct {
+	Server  Server  `yaml:"server"`
+	TLS     TLS     `yaml:"tls"`
+	CORS    CORS    `yaml:"cors"`
+	Log     Log     `yaml:"log"`
+	Cache   Cache   `yaml:"cache"`
+	Candles Candles `yaml:"candles"`
+	Symbols Symbols `yaml:"symbols"`
+	Storage Storage `yaml:"storage"`
+	Auth    Auth    `yaml:"auth"`
+	Limits  Limits  `yaml:"limits"`
+}
+
+type Server struct {
+	Port            int      `yaml:"port"`
+	ReadTimeout     Duration `yaml:"read_timeout"`
+	WriteTimeout    Duration `yaml:"write_timeout"`
+	IdleTimeout     Duration `yaml:"idle_timeout"`
+	ShutdownTimeout Duration `yaml:"shutdown_timeout"`
+	// ShutdownDrain is how long /readyz fails before shutting down.
+	ShutdownDrain Duration `yaml:"shutdown_drain"`
+}
+
+type TLS struct {
+	// CertFile and KeyFile are the PEM certificate and key served,
+	// when empty the server serves plain HTTP. Both are loaded again
+	// whenever either file changes.
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// MinVersion is either 1.2 or 1.3.
+	MinVersion string `yaml:"min_version"`
+	// CipherSuites are the names of the TLS 1.2 cipher suites allowed,
+	// when empty Go's secure defaults are, TLS 1.3 suites aren't
+	// configurable.
+	CipherSuites []string `yaml:"cipher_suites"`
+	// ClientCerts is one of none, ingest or all, with ClientCAFile
+	// the PEM certificates client certificates are verified against.
+	ClientCerts  string `yaml:"client_certs"`
+	ClientCAFile string `yaml:"client_ca_file"`
+}
+
+type CORS struct {
+	// AllowedOrigins are the origins browsers may call the server from,
+	// like https://charts.example.com, or * for any. Disabled when empty.
+	AllowedOrigins []string `yaml:"allowed_origins"`
+	AllowedMethods []string `yaml:"allowed_methods"`
+	AllowedHeaders []string `yaml:"allowed_headers"`
+	// ExposedHeaders are the response headers scripts can read.
+	ExposedHeaders []string `yaml:"exposed_headers"`
+	// MaxAge is how long browsers may cache preflight responses.
+	MaxAge Duration `yaml:"max_age"`
+}
+
+type Log struct {
+	// Level is one of debug, info, warn or error.
+	Level string `yaml:"level"`
+}
+
+type Cache struct {
+	// Limit is the number of latest trades cached for
+	// each symbol, SymbolLimits overrides it per symbol.
+	// The symbols are normalized like those of ingested trades.
+	Limit        int            `yaml:"limit"`
+	SymbolLimits map[string]int `yaml:"symbol_limits"`
+}
+
+type Candles struct {
+	// Intervals are the candle intervals built, e.g. "1m" or "4h",
+	// which must all be multiples of the finest one.
+	Intervals []string `yaml:"intervals"`
+	// LatePolicy is one of accept, reject or corrections.
+	LatePolicy      string   `yaml:"late_policy"`
+	AllowedLateness Duration `yaml:"allowed_lateness"`
+	// Bars are SYMBOL:TYPE=SIZE entries, * for every symbol. The
+	// symbols are normalized like those of ingested trades.
+	Bars []string `yaml:"bars"`
+}
+
+type Symbols struct {
+	// File and AliasesFile are the paths of the JSON symbol
+	// registry and symbol aliases, both optional.
+	File        string `yaml:"file"`
+	AliasesFile string `yaml:"aliases_file"`
+}
+
+type Storage struct {
+	// Backend stores the trade history, only memory is supported.
+	Backend string `yaml:"backend"`
+}
+
+type Auth struct {
+	// Enabled requires an API key granting the role
+	// of the endpoint on every request but health checks.
+	Enabled bool     `yaml:"enabled"`
+	Keys    []APIKey `yaml:"keys"`
+	Signing Signing  `yaml:"signing"`
+}
+
+type APIKey struct {
+	// Name identifies the key in logs.
+	Name string `yaml:"name"`
+	// Hash is the hex SHA-256 hash of the key, as
+	// printed with the key by -gen-api-key.
+	Hash string `yaml:"hash"`
+	// Roles are any of read, ingest or admin.
+	Roles []string `yaml:"roles"`
+}
+
+type Signing struct {
+	// Secrets is keyed by ID and contains the secrets batches ingested
+	// are signed with, when empty batches don't need to be signed.
+	Secrets map[string]string `yaml:"secrets"`
+	// MaxSkew is how far the time a batch was signed
+	// at may be from the clock of the server.
+	MaxSkew Duration `yaml:"max_skew"`
+}
+
+type Limits struct {
+	// MaxBodyBytes limits the size of request bodies, 0 for no limit.
+	MaxBodyBytes int64 `yaml:"max_body_bytes"`
+	// MaxTradesPerBatch limits the trades of an ingest, 0 for no limit.
+	MaxTradesPerBatch int `yaml:"max_trades_per_batch"`
+	// IngestRate is the trades a second, and ReadRate the read requests
+	// a second, each API key or IP can send, 0 for no limit. The bursts
+	// default to the rate, and the ingest burst to at least the max
+	// trades per batch.
+	IngestRate  int `yaml:"ingest_rate"`
+	IngestBurst int `yaml:"ingest_burst"`
+	ReadRate    int `yaml:"read_rate"`
+	ReadBurst   int `yaml:"read_burst"`
+}
+
+// Default returns the configuration used when nothing is overridden.
+func Default() *Config {
+	intervals := make([]string, 0, len(logic.BuilderIntervals))
+	for _, intvl := range logic.BuilderIntervals {
+		intervals = append(intervals, logic.FormatBuilderInterval(intvl))
+	}
+
+	return &Config{
+		Server: Server{
+			Port:            9001,
+			ReadTimeout:     Duration(30 * time.Second),
+			WriteTimeout:    Duration(30 * time.Second),
+			IdleTimeout:     Duration(2 * time.Minute),
+			ShutdownTimeout: Duration(10 * time.Second),
+		},
+		TLS: TLS{
+			MinVersion:   "1.2",
+			CipherSuites: []string{},
+			ClientCerts:  string(auth.ClientCertsNone),
+		},
+		CORS: CORS{
+			AllowedOrigins: []string{},
+			AllowedMethods: []string{http.MethodGet},
+			AllowedHeaders: []string{"Authorization", "Content-Type", "X-API-Key", "X-Request-ID"},
+			ExposedHeaders: []string{"X-Next-Cursor", "X-Prev-Cursor", "X-Request-ID", "Retry-After"},
+			MaxAge:         Duration(10 * time.Minute),
+		},
+		Log:   Log{Level: "info"},
+		Cache: Cache{Limit: 50, SymbolLimits: map[string]int{}},
+		Candles: Candles{
+			Intervals:       intervals,
+			LatePolicy:      logic.LatePolicyAccept.String(),
+			AllowedLateness: Duration(5 * time.Minute),
+			Bars:            []string{},
+		},
+		Storage: Storage{Backend: "memory"},
+		Auth: Auth{
+			Keys:    []APIKey{},
+			Signing: Signing{Secrets: map[string]string{}, MaxSkew: Duration(30 * time.Second)},
+		},
+		Limits: Limits{MaxBodyBytes: 16 << 20},
+	}
+}
+
+// Load returns the default configuration overridden by the YAML file
+// at path, if any, and the environment variables found by lookupEnv.
+//
+// The result isn't validated as flags may still override it.
+func Load(path string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	c := Default()
+
+	if path != "" {
+		payload, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		// Unknown keys are errors so typos don't go unnoticed.
+		dec := yaml.NewDecoder(bytes.NewReader(payload))
+		dec.KnownFields(true)
+		if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(c, lookupEnv); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Validate returns every problem of the configuration joined together.
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		fail("server.port %d is not a valid port", c.Server.Port)
+	}
+	for name, d := range map[string]Duration{
+		"server.read_timeout":      c.Server.ReadTimeout,
+		"server.write_timeout":     c.Server.WriteTimeout,
+		"server.idle_timeout":      c.Server.IdleTimeout,
+		"server.shutdown_timeout":  c.Server.ShutdownTimeout,
+		"server.shutdown_drain":    c.Server.ShutdownDrain,
+		"candles.allowed_lateness": c.Candles.AllowedLateness,
+		"cors.max_age":             c.CORS.MaxAge,
+	} {
+		if d < 0 {
+			fail("%s must not be negative", name)
+		}
+	}
+
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		fail("tls.cert_file and tls.key_file must be set together")
+	}
+	if _, err := c.TLSMinVersion(); err != nil {
+		fail("tls.min_version: %w", err)
+	}
+	if _, err := c.TLSCipherSuites(); err != nil {
+		fail("tls.cipher_suites: %w", err)
+	}
+	if certs, ok := auth.ParseClientCerts(c.TLS.ClientCerts); !ok {
+		fail("tls.client_certs %q is not one of none, ingest or all", c.TLS.ClientCerts)
+	} else if certs != auth.ClientCertsNone && (c.TLS.CertFile == "" || c.TLS.ClientCAFile == "") {
+		fail("tls.client_certs %s requires tls.cert_file and tls.client_ca_file", certs)
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" || origin != u.Scheme+"://"+u.Host {
+			fail("cors.allowed_origins: %q is not an origin like https://example.com", origin)
+		}
+	}
+	for _, method := range c.CORS.AllowedMethods {
+		if method != strings.ToUpper(method) || strings.ContainsAny(method, " ,") {
+			fail("cors.allowed_methods: %q is not an upper case method", method)
+		}
+	}
+
+	if _, err := c.LogLevel(); err != nil {
+		fail("log.level: %w", err)
+	}
+
+	normalizer, err := c.Normalizer()
+	if err != nil {
+		fail("symbols.aliases_file: %w", err)
+		// Symbols are still upper cased without the aliases.
+		normalizer, _ = logic.NewSymbolNormalizer(models.SymbolNormalization{})
+	}
+
+	if c.Cache.Limit <= 0 {
+		fail("cache.limit must be positive")
+	}
+	for symbol, limit := range c.Cache.SymbolLimits {
+		if limit <= 0 {
+			fail("cache.symbol_limits: limit of %s must be positive", symbol)
+		}
+	}
+	if _, err := c.SymbolCacheLimits(normalizer); err != nil {
+		fail("cache.symbol_limits: %w", err)
+	}
+
+	if _, err := c.Intervals(); err != nil {
+		fail("candles.intervals: %w", err)
+	}
+	if _, ok := logic.ParseLatePolicy(c.Candles.LatePolicy); !ok {
+		fail("candles.late_policy %q is not one of accept, reject or corrections", c.Candles.LatePolicy)
+	}
+	if _, err := c.Bars(normalizer); err != nil {
+		fail("candles.bars: %w", err)
+	}
+
+	if c.Storage.Backend != "memory" {
+		fail("storage.backend %q is not supported, only memory is", c.Storage.Backend)
+	}
+
+	if _, err := c.Keyring(); err != nil {
+		fail("auth.keys: %w", err)
+	}
+	if c.Auth.Enabled && len(c.Auth.Keys) == 0 {
+		fail("auth.keys are required when auth is enabled")
+	}
+	if _, err := c.Verifier(); err != nil {
+		fail("auth.signing: %w", err)
+	}
+
+	for name, limit := range map[string]int64{
+		"limits.max_body_bytes":       c.Limits.MaxBodyBytes,
+		"limits.max_trades_per_batch": int64(c.Limits.MaxTradesPerBatch),
+		"limits.ingest_rate":          int64(c.Limits.IngestRate),
+		"limits.ingest_burst":         int64(c.Limits.IngestBurst),
+		"limits.read_rate":            int64(c.Limits.ReadRate),
+		"limits.read_burst":           int64(c.Limits.ReadBurst),
+	} {
+		if limit < 0 {
+			fail("%s must not be negative", name)
+		}
+	}
+	if c.Limits.IngestRate > 0 && c.Limits.IngestBurst > 0 && c.Limits.IngestBurst < c.Limits.MaxTradesPerBatch {
+		fail("limits.ingest_burst must be at least limits.max_trades_per_batch")
+	}
+
+	// Sort so the errors are stable across runs.
+	slices.SortFunc(errs, func(a, b error) int {
+		return strings.Compare(a.Error(), b.Error())
+	})
+	return errors.Join(errs...)
+}
+
+// Keyring returns the keyring of the Auth.Keys,
+// or nil when auth isn't enabled.
+func (c *Config) Keyring() (*auth.Keyring, error) {
+	keys := make([]auth.Key, 0, len(c.Auth.Keys))
+	for _, k := range c.Auth.Keys {
+		hash, err := auth.ParseHash(k.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", k.Name, err)
+		}
+
+		key := auth.Key{Name: k.Name, Hash: hash}
+		for _, name := range k.Roles {
+			role, ok := auth.ParseRole(name)
+			if !ok {
+				return nil, fmt.Errorf("key %s: role %q is not one of read, ingest or admin", k.Name, name)
+			}
+			key.Roles = append(key.Roles, role)
+		}
+		keys = append(keys, key)
+	}
+
+	keyring, err := auth.NewKeyring(keys)
+	if err != nil || !c.Auth.Enabled {
+		return nil, err
+	}
+	return keyring, nil
+}
+
+// Verifier returns the verifier of the Auth.Signing secrets,
+// or nil when there are none.
+func (c *Config) Verifier() (*auth.Verifier, error) {
+	verifier, err := auth.NewVerifier(c.Auth.Signing.Secrets, time.Duration(c.Auth.Signing.MaxSkew))
+	if err != nil || len(c.Auth.Signing.Secrets) == 0 {
+		return nil, err
+	}
+	return verifier, nil
+}
+
+// RequiresRestart reports whether next changes any of the settings
+// that are only applied on startup, the others being reloadable.
+func (c *Config) RequiresRestart(next *Config) bool {
+	a, b := *c, *next
+	a.Cache, b.Cache = Cache{}, Cache{}
+	a.Auth, b.Auth = Auth{}, Auth{}
+	a.Limits, b.Limits = Limits{}, Limits{}
+	a.Log, b.Log = Log{}, Log{}
+	a.CORS, b.CORS = CORS{}, CORS{}
+	a.Symbols.File, b.Symbols.File = "", ""
+	return !reflect.DeepEqual(a, b)
+}
+
+// TLSMinVersion returns the parsed TLS.MinVersion.
+func (c *Config) TLSMinVersion() (uint16, error) {
+	switch c.TLS.MinVersion {
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("%q is not one of 1.2 or 1.3", c.TLS.MinVersion)
+}
+
+// TLSCipherSuites returns the IDs of the TLS.CipherSuites, which must
+// all be secure, or nil to use the defaults.
+func (c *Config) TLSCipherSuites() ([]uint16, error) {
+	if len(c.TLS.CipherSuites) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint16, 0, len(c.TLS.CipherSuites))
+	for _, name := range c.TLS.CipherSuites {
+		i := slices.IndexFunc(tls.CipherSuites(), func(s *tls.CipherSuite) bool {
+			return s.Name == name
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("%q is not a secure cipher suite", name)
+		}
+		ids = append(ids, tls.CipherSuites()[i].ID)
+	}
+	return ids, nil
+}
+
+// ClientCerts returns the parsed TLS.ClientCerts.
+func (c *Config) ClientCerts() auth.ClientCerts {
+	certs, _ := auth.ParseClientCerts(c.TLS.ClientCerts)
+	return certs
+}
+
+// LogLevel returns the parsed Log.Level.
+func (c *Config) LogLevel() (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(c.Log.Level))
+	return level, err
+}
+
+// LatePolicy returns the parsed Candles.LatePolicy.
+func (c *Config) LatePolicy() logic.LatePolicy {
+	policy, _ := logic.ParseLatePolicy(c.Candles.LatePolicy)
+	return policy
+}
+
+// Intervals returns the parsed Candles.Intervals sorted.
+func (c *Config) Intervals() ([]logic.BuilderInterval, error) {
+	if len(c.Candles.Intervals) == 0 {
+		return nil, errors.New("at least one interval is required")
+	}
+
+	intervals := make([]logic.BuilderInterval, 0, len(c.Candles.Intervals))
+	for _, k := range c.Candles.Intervals {
+		intvl, ok := logic.ParseBuilderInterval(k)
+		if !ok {
+			return nil, fmt.Errorf("invalid interval %q", k)
+		}
+		if slices.Contains(intervals, intvl) {
+			return nil, fmt.Errorf("duplicate interval %q", k)
+		}
+		intervals = append(intervals, intvl)
+	}
+	slices.Sort(intervals)
+
+	// Coarser candles are rolled up from the finest ones.
+	for _, intvl := range intervals[1:] {
+		if intvl%intervals[0] != 0 {
+			return nil, fmt.Errorf("interval %s is not a multiple of %s",
+				logic.FormatBuilderInterval(intvl), logic.FormatBuilderInterval(intervals[0]))
+		}
+	}
+	return intervals, nil
+}
+
+// Normalizer loads the symbol normalizer of Symbols.AliasesFile, which
+// only trims and upper cases symbols when there is no such file.
+func (c *Config) Normalizer() (*logic.SymbolNormalizer, error) {
+	if c.Symbols.AliasesFile == "" {
+		return logic.NewSymbolNormalizer(models.SymbolNormalization{})
+	}
+	return logic.LoadSymbolNormalizer(c.Symbols.AliasesFile)
+}
+
+// SymbolCacheLimits returns Cache.SymbolLimits keyed by the symbols
+// normalized by n, which must not be the same once normalized.
+func (c *Config) SymbolCacheLimits(n *logic.SymbolNormalizer) (map[string]int, error) {
+	canonical, err := n.NormalizeAll(slices.Collect(maps.Keys(c.Cache.SymbolLimits)))
+	if err != nil {
+		return nil, err
+	}
+
+	limits := make(map[string]int, len(c.Cache.SymbolLimits))
+	for symbol, limit := range c.Cache.SymbolLimits {
+		limits[canonical[symbol]] = limit
+	}
+	return limits, nil
+}
+
+// Bars returns the parsed Candles.Bars keyed by the symbols normalized
+// by n, which must not be the same once normalized, or "*".
+func (c *Config) Bars(n *logic.SymbolNormalizer) (map[string][]logic.BarBuilderParams, error) {
+	bars := make(map[string][]logic.BarBuilderParams)
+	for _, entry := range c.Candles.Bars {
+		symbol, spec, ok := strings.Cut(entry, ":")
+		if !ok || symbol == "" {
+			return nil, fmt.Errorf("expected SYMBOL:TYPE=SIZE, got %q", entry)
+		}
+		typeName, val, ok := strings.Cut(spec, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected SYMBOL:TYPE=SIZE, got %q", entry)
+		}
+
+		typ, ok := logic.ParseBarType(typeName)
+		if !ok {
+			return nil, fmt.Errorf("invalid bar type %q for %s", typeName, symbol)
+		}
+		size, err := strconv.ParseFloat(val, 64)
+		if err != nil || size <= 0 || (typ == logic.BarTypeTick && size != float64(int64(size))) {
+			return nil, fmt.Errorf("invalid %s bar size %q for %s", typeName, val, symbol)
+		}
+		bars[symbol] = append(bars[symbol], logic.BarBuilderParams{Type: typ, Size: size})
+	}
+
+	symbols := make([]string, 0, len(bars))
+	for symbol := range bars {
+		if symbol != "*" {
+			symbols = append(symbols, symbol)
+		}
+	}
+	canonical, err := n.NormalizeAll(symbols)
+	if err != nil {
+		return nil, err
+	}
+	for symbol, canonicalSymbol := range canonical {
+		if symbol != canonicalSymbol {
+			bars[canonicalSymbol] = bars[symbol]
+			delete(bars, symbol)
+		}
+	}
+	return bars, nil
+}
+
+// YAML returns the configuration as it would be written in a file,
+// with the signing secrets redacted.
+func (c *Config) YAML() ([]byte, error) {
+	redacted := *c
+	redacted.Auth.Signing.Secrets = make(map[string]string, len(c.Auth.Signing.Secrets))
+	for id := range c.Auth.Signing.Secrets {
+		redacted.Auth.Signing.Secrets[id] = "REDACTED"
+	}
+	return yaml.Marshal(&redacted)
+}
+
+// Duration is a time.Duration written like "10s" in YAML.
+type Duration time.Duration
+
+func (d Duration) MarshalYAML() (any, error) {
+	return time.Duration(d).String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	return d.UnmarshalText([]byte(node.Value))
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
@@ -0,0 +1,291 @@
+package config
+
+import (
+	"crypto/tls"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/infinityCounter2/vh-trader/internal/auth"
+	"github.com/infinityCounter2/vh-trader/internal/logic"
+)
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600), "Failed to write config file")
+	return path
+}
+
+func envOf(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		val, ok := env[key]
+		return val, ok
+	}
+}
+
+func TestDefaultIsValid(t *testing.T) {
+	c := Default()
+	require.NoError(t, c.Validate(), "Expected the default config to be valid")
+
+	intervals, err := c.Intervals()
+	require.NoError(t, err)
+	require.Equal(t, logic.BuilderIntervals, intervals, "Expected the default intervals")
+}
+
+func TestLoad_Layers(t *testing.T) {
+	path := writeFile(t, `
+server:
+  port: 8080
+  shutdown_timeout: 3s
+cache:
+  limit: 100
+candles:
+  intervals: [1m, 1h]
+`)
+
+	c, err := Load(path, envOf(map[string]string{
+		"VH_SERVER_PORT":         "9090",
+		"VH_CACHE_SYMBOL_LIMITS": "BTC_USD=10, ETH_USD=20",
+		"VH_CANDLES_BARS":        "BTC_USD:tick=100,*:renko=5",
+		"VH_LOG_LEVEL":           "debug",
+	}))
+	require.NoError(t, err)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := RegisterFlags(fs)
+	require.NoError(t, fs.Parse([]string{"-cache-limit", "200", "-intervals", "5m,15m"}))
+	require.NoError(t, flags.Apply(c))
+	require.NoError(t, c.Validate())
+
+	require.Equal(t, 9090, c.Server.Port, "Expected the env to override the file")
+	require.Equal(t, Duration(3*time.Second), c.Server.ShutdownTimeout, "Expected the file to override the default")
+	require.Equal(t, Duration(30*time.Second), c.Server.ReadTimeout, "Expected the default when not overridden")
+	require.Equal(t, 200, c.Cache.Limit, "Expected the flag to override the file")
+	require.Equal(t, map[string]int{"BTC_USD": 10, "ETH_USD": 20}, c.Cache.SymbolLimits)
+	require.Equal(t, "debug", c.Log.Level)
+
+	intervals, err := c.Intervals()
+	require.NoError(t, err)
+	require.Equal(t, []logic.BuilderInterval{5 * time.Minute, 15 * time.Minute}, intervals,
+		"Expected the flag to override the file")
+
+	normalizer, err := c.Normalizer()
+	require.NoError(t, err)
+	bars, err := c.Bars(normalizer)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]logic.BarBuilderParams{
+		"BTC_USD": {{Type: logic.BarTypeTick, Size: 100}},
+		"*":       {{Type: logic.BarTypeRenko, Size: 5}},
+	}, bars)
+}
+
+func TestNormalizedSymbols(t *testing.T) {
+	aliases := writeFile(t, `{"aliases": {"XBT_USD": "btc_usd"}}`)
+	c := Default()
+	c.Symbols.AliasesFile = aliases
+	c.Cache.SymbolLimits = map[string]int{"xbt_usd": 10, " eth_usd": 20}
+	c.Candles.Bars = []string{"xbt_usd:tick=100", "*:renko=5"}
+	require.NoError(t, c.Validate())
+
+	normalizer, err := c.Normalizer()
+	require.NoError(t, err)
+	limits, err := c.SymbolCacheLimits(normalizer)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"BTC_USD": 10, "ETH_USD": 20}, limits, "Expected the limits of the canonical symbols")
+	bars, err := c.Bars(normalizer)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]logic.BarBuilderParams{
+		"BTC_USD": {{Type: logic.BarTypeTick, Size: 100}},
+		"*":       {{Type: logic.BarTypeRenko, Size: 5}},
+	}, bars, "Expected the bars of the canonical symbols")
+
+	c.Cache.SymbolLimits = map[string]int{"XBT_USD": 10, "btc_usd": 20}
+	c.Candles.Bars = []string{"XBT_USD:tick=100", "BTC_USD:renko=5"}
+	err = c.Validate()
+	require.Error(t, err)
+	require.Equal(t, `cache.symbol_limits: "XBT_USD" and "btc_usd" are both BTC_USD once normalized
+candles.bars: "BTC_USD" and "XBT_USD" are both BTC_USD once normalized`, err.Error(),
+		"Expected the symbols that are the same once normalized to be rejected")
+
+	c.Symbols.AliasesFile = filepath.Join(t.TempDir(), "missing.json")
+	require.ErrorContains(t, c.Validate(), "symbols.aliases_file", "Expected a missing aliases file to fail")
+}
+
+func TestLoad_Errors(t *testing.T) {
+	_, err := Load(writeFile(t, "server:\n  prot: 80\n"), envOf(nil))
+	require.Error(t, err, "Expected unknown keys to fail")
+
+	_, err = Load(writeFile(t, "server:\n  read_timeout: soon\n"), envOf(nil))
+	require.Error(t, err, "Expected invalid durations to fail")
+
+	_, err = Load("", envOf(map[string]string{"VH_CACHE_LIMIT": "many"}))
+	require.ErrorContains(t, err, "VH_CACHE_LIMIT", "Expected the env variable to be named")
+
+	c, err := Load(writeFile(t, ""), envOf(nil))
+	require.NoError(t, err, "Expected an empty file to load")
+	require.Equal(t, Default(), c, "Expected an empty file to keep the defaults")
+}
+
+func TestValidate(t *testing.T) {
+	c := Default()
+	c.Server.Port = 0
+	c.Cache.Limit = -1
+	c.Candles.Intervals = []string{"1m", "90s"}
+	c.Candles.LatePolicy = "ignore"
+	c.Candles.Bars = []string{"BTC_USD:tick=1.5"}
+	c.Storage.Backend = "postgres"
+	c.Server.ShutdownDrain = Duration(-time.Second)
+	c.Limits.ReadRate = -1
+	c.Limits.MaxTradesPerBatch = 1000
+	c.Limits.IngestRate, c.Limits.IngestBurst = 100, 500
+
+	err := c.Validate()
+	require.Error(t, err)
+	require.Equal(t, `cache.limit must be positive
+candles.bars: invalid tick bar size "1.5" for BTC_USD
+candles.intervals: interval 90s is not a multiple of 1m
+candles.late_policy "ignore" is not one of accept, reject or corrections
+limits.ingest_burst must be at least limits.max_trades_per_batch
+limits.read_rate must not be negative
+server.port 0 is not a valid port
+server.shutdown_drain must not be negative
+storage.backend "postgres" is not supported, only memory is`, err.Error(), "Expected every problem to be reported")
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	c := Default()
+	c.Cache.SymbolLimits = map[string]int{"BTC_USD": 10}
+	c.Server.ShutdownDrain = Duration(1500 * time.Millisecond)
+
+	out, err := c.YAML()
+	require.NoError(t, err)
+	require.Contains(t, string(out), "shutdown_drain: 1.5s", "Expected durations to be written as strings")
+
+	loaded, err := Load(writeFile(t, string(out)), envOf(nil))
+	require.NoError(t, err)
+	require.Equal(t, c, loaded, "Expected the printed config to load back unchanged")
+}
+
+func TestRequiresRestart(t *testing.T) {
+	c := Default()
+
+	next := Default()
+	next.Cache.Limit = 100
+	next.Cache.SymbolLimits = map[string]int{"BTC_USD": 10}
+	next.Symbols.File = "symbols.json"
+	next.Auth.Enabled = true
+	next.Limits.IngestRate = 1000
+	next.Log.Level = "debug"
+	next.CORS.AllowedOrigins = []string{"https://example.com"}
+	require.False(t, c.RequiresRestart(next), "Expected cache limits, symbols, auth, limits, log and cors to be reloadable")
+
+	next.Server.Port = 8080
+	require.True(t, c.RequiresRestart(next), "Expected the port to require a restart")
+}
+
+func TestKeyring(t *testing.T) {
+	key := auth.GenerateKey()
+	path := writeFile(t, `
+auth:
+  enabled: true
+  keys:
+    - name: feed
+      hash: `+auth.HashKey(key).String()+`
+      roles: [ingest, read]
+`)
+	c, err := Load(path, envOf(nil))
+	require.NoError(t, err)
+	require.NoError(t, c.Validate())
+
+	keyring, err := c.Keyring()
+	require.NoError(t, err)
+	feed, ok := keyring.Authenticate(key)
+	require.True(t, ok, "Expected the key to authenticate")
+	require.Equal(t, []auth.Role{auth.RoleIngest, auth.RoleRead}, feed.Roles)
+
+	c.Auth.Enabled = false
+	keyring, err = c.Keyring()
+	require.NoError(t, err)
+	require.Nil(t, keyring, "Expected no keyring when auth is disabled")
+
+	c.Auth.Enabled = true
+	c.Auth.Keys[0].Roles = []string{"write"}
+	require.ErrorContains(t, c.Validate(), `role "write"`, "Expected unknown roles to fail")
+
+	c.Auth.Keys = nil
+	require.ErrorContains(t, c.Validate(), "auth.keys are required", "Expected keys to be required")
+}
+
+func TestVerifier(t *testing.T) {
+	secret := auth.GenerateKey()
+	c, err := Load("", envOf(map[string]string{
+		"VH_AUTH_SIGNING_SECRETS":  "feed=" + secret,
+		"VH_AUTH_SIGNING_MAX_SKEW": "10s",
+	}))
+	require.NoError(t, err)
+	require.NoError(t, c.Validate())
+
+	verifier, err := c.Verifier()
+	require.NoError(t, err)
+	require.Equal(t, []string{"feed"}, verifier.IDs())
+	require.Equal(t, 10*time.Second, verifier.MaxSkew())
+
+	out, err := c.YAML()
+	require.NoError(t, err)
+	require.NotContains(t, string(out), secret, "Expected secrets not to be printed")
+
+	c.Auth.Signing.Secrets = map[string]string{}
+	verifier, err = c.Verifier()
+	require.NoError(t, err)
+	require.Nil(t, verifier, "Expected no verifier without secrets")
+
+	c.Auth.Signing.Secrets = map[string]string{"feed": "short"}
+	require.ErrorContains(t, c.Validate(), "at least 32 characters", "Expected short secrets to fail")
+}
+
+func TestTLS(t *testing.T) {
+	c := Default()
+	c.TLS.MinVersion = "1.3"
+	c.TLS.CipherSuites = []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"}
+
+	minVersion, err := c.TLSMinVersion()
+	require.NoError(t, err)
+	require.Equal(t, uint16(tls.VersionTLS13), minVersion)
+	suites, err := c.TLSCipherSuites()
+	require.NoError(t, err)
+	require.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}, suites)
+
+	c.TLS.CipherSuites = []string{"TLS_RSA_WITH_RC4_128_SHA"}
+	_, err = c.TLSCipherSuites()
+	require.Error(t, err, "Expected insecure cipher suites to fail")
+
+	c = Default()
+	c.TLS.CertFile = "cert.pem"
+	c.TLS.ClientCerts = "ingest"
+	require.Equal(t, `tls.cert_file and tls.key_file must be set together
+tls.client_certs ingest requires tls.cert_file and tls.client_ca_file`, c.Validate().Error())
+
+	c.TLS.KeyFile, c.TLS.ClientCAFile = "key.pem", "ca.pem"
+	require.NoError(t, c.Validate())
+	require.Equal(t, auth.ClientCertsIngest, c.ClientCerts())
+}
+
+func TestCORS(t *testing.T) {
+	c, err := Load("", envOf(map[string]string{
+		"VH_CORS_ALLOWED_ORIGINS": "https://charts.example.com, http://localhost:3000",
+	}))
+	require.NoError(t, err)
+	require.NoError(t, c.Validate())
+	require.Equal(t, []string{"https://charts.example.com", "http://localhost:3000"}, c.CORS.AllowedOrigins)
+
+	c.CORS.AllowedOrigins = []string{"*", "charts.example.com", "https://charts.example.com/"}
+	c.CORS.AllowedMethods = []string{"get"}
+	require.Equal(t, `cors.allowed_methods: "get" is not an upper case method
+cors.allowed_origins: "charts.example.com" is not an origin like https://example.com
+cors.allowed_origins: "https://charts.example.com/" is not an origin like https://example.com`, c.Validate().Error())
+}
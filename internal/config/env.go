@@ -0,0 +1,112 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// applyEnv overrides the settings of c with the environment variables
+// named after their YAML path, e.g. VH_CACHE_SYMBOL_LIMITS for
+// cache.symbol_limits. Lists are comma separated and maps are comma
+// separated KEY=VALUE pairs.
+func applyEnv(c *Config, lookupEnv func(string) (string, bool)) error {
+	return applyEnvStruct(reflect.ValueOf(c).Elem(), EnvPrefix, lookupEnv)
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+func applyEnvStruct(v reflect.Value, prefix string, lookupEnv func(string) (string, bool)) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := prefix + "_" + strings.ToUpper(name)
+
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := applyEnvStruct(field, key, lookupEnv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		val, ok := lookupEnv(key)
+		if !ok {
+			continue
+		}
+		if err := setFromString(field, val); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func setFromString(field reflect.Value, val string) error {
+	if field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(val)
+
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", val)
+		}
+		field.SetInt(n)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", val)
+		}
+		field.SetBool(b)
+
+	case reflect.Slice:
+		items := splitList(val)
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setFromString(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+
+	case reflect.Map:
+		m := reflect.MakeMap(field.Type())
+		for _, pair := range splitList(val) {
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok || k == "" {
+				return fmt.Errorf("expected KEY=VALUE, got %q", pair)
+			}
+			elem := reflect.New(field.Type().Elem()).Elem()
+			if err := setFromString(elem, v); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(k), elem)
+		}
+		field.Set(m)
+
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+	return nil
+}
+
+// splitList splits a comma separated list, ignoring empty items.
+func splitList(val string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+)
+
+// Flags are the command line flags overriding the configuration,
+// only the flags given on the command line override it.
+type Flags struct {
+	overrides []func(c *Config) error
+}
+
+// RegisterFlags defines the flags of the settings on fs.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{}
+	f.bind(fs, "port", "The port the server runs on",
+		func(c *Config) any { return &c.Server.Port })
+	f.bind(fs, "shutdown-drain", "How long to keep serving with /readyz failing before shutting down",
+		func(c *Config) any { return &c.Server.ShutdownDrain })
+	f.bind(fs, "tls-cert", "Path to the PEM certificate served over TLS, with -tls-key",
+		func(c *Config) any { return &c.TLS.CertFile })
+	f.bind(fs, "tls-key", "Path to the PEM key of -tls-cert",
+		func(c *Config) any { return &c.TLS.KeyFile })
+	f.bind(fs, "cors-origins", "The origins browsers may call the server from, e.g. https://charts.example.com, * for any",
+		func(c *Config) any { return &c.CORS.AllowedOrigins })
+	f.bind(fs, "log-level", "The minimum level logged: debug, info, warn or error",
+		func(c *Config) any { return &c.Log.Level })
+	f.bind(fs, "cache-limit", "The number of latest trades cached for each symbol",
+		func(c *Config) any { return &c.Cache.Limit })
+	f.bind(fs, "symbol-cache-limits", "Per symbol overrides of -cache-limit, e.g. BTC_USD=10000,FOO_USD=100",
+		func(c *Config) any { return &c.Cache.SymbolLimits })
+	f.bind(fs, "intervals", "The candle intervals built, e.g. 1m,5m,15m,1h",
+		func(c *Config) any { return &c.Candles.Intervals })
+	f.bind(fs, "late-policy", "How trades for closed candles are handled: accept, reject or corrections",
+		func(c *Config) any { return &c.Candles.LatePolicy })
+	f.bind(fs, "allowed-lateness", "How far behind the newest trade a late trade may be and still amend a candle, 0 for no limit",
+		func(c *Config) any { return &c.Candles.AllowedLateness })
+	f.bind(fs, "bars", "Bars built per symbol, * for every symbol, e.g. BTC_USD:tick=100,BTC_USD:renko=50,*:dollar=1000000",
+		func(c *Config) any { return &c.Candles.Bars })
+	f.bind(fs, "symbols", "Path to a JSON file defining the symbol registry",
+		func(c *Config) any { return &c.Symbols.File })
+	f.bind(fs, "symbol-aliases", "Path to a JSON file of symbol aliases and normalization rules",
+		func(c *Config) any { return &c.Symbols.AliasesFile })
+	f.bind(fs, "max-body-bytes", "The maximum size of request bodies, 0 for no limit",
+		func(c *Config) any { return &c.Limits.MaxBodyBytes })
+	f.bind(fs, "max-trades-per-batch", "The maximum number of trades of an ingest, 0 for no limit",
+		func(c *Config) any { return &c.Limits.MaxTradesPerBatch })
+	f.bind(fs, "ingest-rate", "The trades a second each API key or IP can ingest, 0 for no limit",
+		func(c *Config) any { return &c.Limits.IngestRate })
+	f.bind(fs, "read-rate", "The read requests a second each API key or IP can send, 0 for no limit",
+		func(c *Config) any { return &c.Limits.ReadRate })
+	return f
+}
+
+// bind defines a flag setting the field returned by setting,
+// parsed like the environment variables are.
+func (f *Flags) bind(fs *flag.FlagSet, name, usage string, setting func(c *Config) any) {
+	fs.Func(name, usage, func(val string) error {
+		// Check the value now so that errors name the flag.
+		if err := setFromString(reflect.ValueOf(setting(Default())).Elem(), val); err != nil {
+			return err
+		}
+
+		f.overrides = append(f.overrides, func(c *Config) error {
+			if err := setFromString(reflect.ValueOf(setting(c)).Elem(), val); err != nil {
+				return fmt.Errorf("-%s: %w", name, err)
+			}
+			return nil
+		})
+		return nil
+	})
+}
+
+// Apply overrides the settings of c with the flags given.
+func (f *Flags) Apply(c *Config) error {
+	for _, override := range f.overrides {
+		if err := override(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
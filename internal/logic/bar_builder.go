@@ -0,0 +1,273 @@
+package logic
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+)
+
+// BarType selects how a BarBuilder splits trades into bars.
+type BarType int
+
+const (
+	// BarTypeTick closes a bar every Size trades.
+	BarTypeTick BarType = iota
+	// BarTypeVolume closes a bar once it holds Size base units.
+	BarTypeVolume
+	// BarTypeDollar closes a bar once it holds Size quote notional.
+	BarTypeDollar
+	// BarTypeRenko builds bricks of Size price movement.
+	BarTypeRenko
+	// BarTypeRange closes a bar once its high and low are Size apart.
+	BarTypeRange
+)
+
+var barTypeNames = map[BarType]string{
+	BarTypeTick:   "tick",
+	BarTypeVolume: "volume",
+	BarTypeDollar: "dollar",
+	BarTypeRenko:  "renko",
+	BarTypeRange:  "range",
+}
+
+func (t BarType) String() string {
+	return barTypeNames[t]
+}
+
+// ParseBarType returns the BarType with the given name.
+func ParseBarType(name string) (BarType, bool) {
+	for t, n := range barTypeNames {
+		if n == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+type BarBuilderParams struct {
+	Type BarType
+	// Size is the threshold closing a bar, in the unit of the Type,
+	// which is price for renko and range bars.
+	Size float64
+}
+
+// String returns the params as "type_size", e.g. "tick_100".
+func (p BarBuilderParams) String() string {
+	return p.Type.String() + "_" + strconv.FormatFloat(p.Size, 'f', -1, 64)
+}
+
+// BarBuilder builds bars sampled by trading activity instead of time.
+//
+// Bars are built from the trades in the order they are processed, so
+// unlike candles late trades land in the current bar. Tick, volume and
+// dollar bars close on the trade that reaches the threshold, trades are
+// never split across bars, so bars may hold more than Size.
+//
+// Range bars close before a trade that would take them beyond Size,
+// and renko bricks are only built once complete, so there is never
+// a current brick.
+//
+// The bars are candles whose Timestamp is the time of their last trade.
+//
+// Trades are numbered in the order they are processed, from 0, which
+// must be the order of TradeHistory.Seq so that bars can be rebuilt.
+type BarBuilder struct {
+	p BarBuilderParams
+
+	mtx     sync.RWMutex
+	closed  []models.Candle
+	current *models.Candle
+	// fill is the progress of the current bar towards Size, and for
+	// renko bricks the volume of the trades since the last brick.
+	fill float64
+
+	// top and bottom are the prices of the last renko brick,
+	// both set to the first trade price until a brick is built.
+	top      float64
+	bottom   float64
+	anchored bool
+
+	// next is the seq of the next trade processed.
+	next int
+	// checkpoints are the states between bars, sorted by seq.
+	checkpoints []barCheckpoint
+}
+
+// barCheckpoint is the state of a BarBuilder between two bars, from
+// which the bars of the trades after it can be built again.
+type barCheckpoint struct {
+	// seq is that of the first trade after the checkpoint.
+	seq int
+	// bars is the number of closed bars.
+	bars     int
+	top      float64
+	bottom   float64
+	anchored bool
+}
+
+func NewBarBuilder(p BarBuilderParams) *BarBuilder {
+	return &BarBuilder{p: p}
+}
+
+// ProcessTrades batch updates the BarBuilder with the trades given.
+func (b *BarBuilder) ProcessTrades(trades []models.Trade) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	for _, t := range trades {
+		b.processTrade(t)
+		b.next++
+	}
+}
+
+// Rebuild discards the bars from the one holding the trade with the given
+// seq and builds them again, used when a trade already processed is
+// cancelled or corrected. The bars are rebuilt from the trades returned by
+// since, called with the seq of the first trade of the first bar discarded,
+// which also returns the seq of the next trade to process.
+func (b *BarBuilder) Rebuild(seq int, since func(seq int) ([]IngestedTrade, int)) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	i := sort.Search(len(b.checkpoints), func(i int) bool {
+		return b.checkpoints[i].seq > seq
+	})
+	cp := barCheckpoint{}
+	if i > 0 {
+		cp = b.checkpoints[i-1]
+	}
+
+	b.closed = b.closed[:cp.bars]
+	b.checkpoints = b.checkpoints[:i]
+	b.current = nil
+	b.fill = 0
+	b.top, b.bottom, b.anchored = cp.top, cp.bottom, cp.anchored
+
+	trades, next := since(cp.seq)
+	for _, t := range trades {
+		b.next = t.Seq
+		b.processTrade(t.Trade)
+	}
+	b.next = next
+}
+
+// checkpoint records the state of the builder between two bars, seq
+// being that of the first trade after it. The current bar must be empty.
+func (b *BarBuilder) checkpoint(seq int) {
+	b.checkpoints = append(b.checkpoints, barCheckpoint{
+		seq:      seq,
+		bars:     len(b.closed),
+		top:      b.top,
+		bottom:   b.bottom,
+		anchored: b.anchored,
+	})
+}
+
+func (b *BarBuilder) processTrade(t models.Trade) {
+	switch b.p.Type {
+	case BarTypeRenko:
+		b.processRenkoTrade(t)
+		return
+	case BarTypeRange:
+		b.processRangeTrade(t)
+		return
+	}
+
+	if b.current == nil {
+		b.current = &models.Candle{}
+	}
+	updateCandle(b.current, t)
+	b.current.Timestamp = max(b.current.Timestamp, t.Timestamp)
+
+	switch b.p.Type {
+	case BarTypeTick:
+		b.fill++
+	case BarTypeVolume:
+		b.fill += t.Size
+	case BarTypeDollar:
+		b.fill += t.Size * t.Price
+	}
+
+	if b.fill >= b.p.Size {
+		b.closed = append(b.closed, *b.current)
+		b.current = nil
+		b.fill = 0
+		b.checkpoint(b.next + 1)
+	}
+}
+
+// processRenkoTrade builds the bricks completed by the trade. A brick in
+// the direction of the last one needs a move of Size beyond it, while a
+// reversal needs a move of Size beyond the other side of the last brick.
+func (b *BarBuilder) processRenkoTrade(t models.Trade) {
+	if !b.anchored {
+		b.top, b.bottom = t.Price, t.Price
+		b.anchored = true
+	}
+	b.fill += t.Size * t.Price
+	bricks := len(b.closed)
+
+	for t.Price >= b.top+b.p.Size {
+		b.closeBrick(t, b.top, b.top+b.p.Size)
+		b.bottom, b.top = b.top, b.top+b.p.Size
+	}
+	for t.Price <= b.bottom-b.p.Size {
+		b.closeBrick(t, b.bottom, b.bottom-b.p.Size)
+		b.top, b.bottom = b.bottom, b.bottom-b.p.Size
+	}
+	if len(b.closed) > bricks {
+		b.checkpoint(b.next + 1)
+	}
+}
+
+// closeBrick appends a renko brick closed by the trade, the volume
+// since the last brick goes to the first brick the trade builds.
+func (b *BarBuilder) closeBrick(t models.Trade, open, close float64) {
+	b.closed = append(b.closed, models.Candle{
+		Open:      open,
+		High:      max(open, close),
+		Low:       min(open, close),
+		Close:     close,
+		Volume:    b.fill,
+		Timestamp: t.Timestamp,
+	})
+	b.fill = 0
+}
+
+// processRangeTrade adds the trade to the current range bar, or closes it
+// and starts a new one if the trade would take the bar beyond Size.
+func (b *BarBuilder) processRangeTrade(t models.Trade) {
+	if b.current != nil && max(b.current.High, t.Price)-min(b.current.Low, t.Price) > b.p.Size {
+		b.closed = append(b.closed, *b.current)
+		b.current = nil
+		b.checkpoint(b.next)
+	}
+
+	if b.current == nil {
+		b.current = &models.Candle{}
+	}
+	updateCandle(b.current, t)
+	b.current.Timestamp = max(b.current.Timestamp, t.Timestamp)
+
+	if b.current.High-b.current.Low >= b.p.Size {
+		b.closed = append(b.closed, *b.current)
+		b.current = nil
+		b.checkpoint(b.next + 1)
+	}
+}
+
+// GetCandles returns the closed bars followed by the current
+// bar if any, in the order they were built.
+func (b *BarBuilder) GetCandles() models.CandleList {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+
+	candles := make(models.CandleList, len(b.closed), len(b.closed)+1)
+	copy(candles, b.closed)
+	if b.current != nil {
+		candles = append(candles, *b.current)
+	}
+	return candles
+}
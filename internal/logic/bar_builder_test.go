@@ -0,0 +1,154 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func barTrades(prices ...float64) []models.Trade {
+	trades := make([]models.Trade, 0, len(prices))
+	for i, p := range prices {
+		trades = append(trades, models.Trade{
+			TradeID:   string(rune('a' + i)),
+			Timestamp: int64(1000 + i),
+			Price:     p,
+			Size:      2,
+		})
+	}
+	return trades
+}
+
+func TestBarBuilder_TickBars(t *testing.T) {
+	builder := NewBarBuilder(BarBuilderParams{Type: BarTypeTick, Size: 2})
+	builder.ProcessTrades(barTrades(10, 12, 11, 9, 13))
+
+	require.Equal(t, models.CandleList{
+		{Timestamp: 1001, Open: 10, High: 12, Low: 10, Close: 12, Volume: 44},
+		{Timestamp: 1003, Open: 11, High: 11, Low: 9, Close: 9, Volume: 40},
+		{Timestamp: 1004, Open: 13, High: 13, Low: 13, Close: 13, Volume: 26},
+	}, builder.GetCandles(), "Tick bars mismatch")
+}
+
+func TestBarBuilder_VolumeBars(t *testing.T) {
+	builder := NewBarBuilder(BarBuilderParams{Type: BarTypeVolume, Size: 5})
+	builder.ProcessTrades(barTrades(10, 10, 10, 10))
+
+	candles := builder.GetCandles()
+	// Trades aren't split, so the first bar holds 6 base units.
+	require.Len(t, candles, 2, "Expected a closed and a current bar")
+	require.Equal(t, int64(1002), candles[0].Timestamp, "First bar should close on the third trade")
+	require.Equal(t, int64(1003), candles[1].Timestamp, "Current bar mismatch")
+}
+
+func TestBarBuilder_DollarBars(t *testing.T) {
+	builder := NewBarBuilder(BarBuilderParams{Type: BarTypeDollar, Size: 40})
+	builder.ProcessTrades(barTrades(10, 25, 5, 5))
+
+	candles := builder.GetCandles()
+	require.Len(t, candles, 2, "Expected two closed bars")
+	require.Equal(t, 70.0, candles[0].Volume, "First bar volume mismatch")
+	require.Equal(t, 20.0, candles[1].Volume, "Second bar volume mismatch")
+	require.Equal(t, int64(1003), candles[1].Timestamp, "Second bar should stay open until it reaches the size")
+}
+
+func TestBarBuilder_Rebuild(t *testing.T) {
+	builder := NewBarBuilder(BarBuilderParams{Type: BarTypeTick, Size: 2})
+	trades := barTrades(10, 12, 11, 9, 13)
+	builder.ProcessTrades(trades)
+
+	// Cancelling the fourth trade only rebuilds from the second bar.
+	var from int
+	builder.Rebuild(3, func(seq int) ([]IngestedTrade, int) {
+		from = seq
+		return []IngestedTrade{{Seq: 2, Trade: trades[2]}, {Seq: 4, Trade: trades[4]}}, 5
+	})
+	require.Equal(t, 2, from, "Expected the bars to be rebuilt from the first trade of the second bar")
+	require.Equal(t, models.CandleList{
+		{Timestamp: 1001, Open: 10, High: 12, Low: 10, Close: 12, Volume: 44},
+		{Timestamp: 1004, Open: 11, High: 13, Low: 11, Close: 13, Volume: 48},
+	}, builder.GetCandles(), "Bars mismatch after rebuild")
+
+	// Trades processed after a rebuild take the seqs after it.
+	builder.ProcessTrades(barTrades(14))
+	builder.Rebuild(5, func(seq int) ([]IngestedTrade, int) {
+		from = seq
+		return nil, 6
+	})
+	require.Equal(t, 5, from, "Expected only the current bar to be rebuilt")
+	require.Len(t, builder.GetCandles(), 2, "Expected the current bar to be discarded")
+
+	builder.Rebuild(0, func(seq int) ([]IngestedTrade, int) {
+		from = seq
+		return nil, 6
+	})
+	require.Equal(t, 0, from, "Expected the bars to be rebuilt from the first trade")
+	require.Empty(t, builder.GetCandles(), "Expected every bar to be discarded")
+}
+
+func TestBarBuilder_RebuildRenko(t *testing.T) {
+	trades := barTrades(100, 125, 110, 90, 75, 105)
+	ingested := make([]IngestedTrade, len(trades))
+	for i, trade := range trades {
+		ingested[i] = IngestedTrade{Seq: i, Trade: trade}
+	}
+
+	built := NewBarBuilder(BarBuilderParams{Type: BarTypeRenko, Size: 10})
+	built.ProcessTrades(trades)
+	expected := built.GetCandles()
+
+	// Correcting the fifth trade to the same price builds the same bricks.
+	builder := NewBarBuilder(BarBuilderParams{Type: BarTypeRenko, Size: 10})
+	builder.ProcessTrades(trades)
+	builder.Rebuild(4, func(seq int) ([]IngestedTrade, int) {
+		require.Positive(t, seq, "Expected the bricks before the trade to be kept")
+		return ingested[seq:], len(trades)
+	})
+	require.Equal(t, expected, builder.GetCandles(), "Bricks mismatch after rebuild")
+}
+
+func TestParseBarType(t *testing.T) {
+	for typ := range barTypeNames {
+		parsed, ok := ParseBarType(typ.String())
+		require.Truef(t, ok, "Expected %s to parse", typ)
+		require.Equal(t, typ, parsed, "Bar type mismatch")
+	}
+
+	_, ok := ParseBarType("time")
+	require.False(t, ok, "Expected time not to be a bar type")
+}
+
+func TestBarBuilder_RenkoBricks(t *testing.T) {
+	builder := NewBarBuilder(BarBuilderParams{Type: BarTypeRenko, Size: 10})
+	// Up two bricks at once, then a pullback short of a reversal.
+	builder.ProcessTrades(barTrades(100, 125, 112, 101))
+
+	candles := builder.GetCandles()
+	require.Len(t, candles, 2, "Expected two bricks without a current one")
+	require.Equal(t, models.Candle{Timestamp: 1001, Open: 100, High: 110, Low: 100, Close: 110, Volume: 450}, candles[0], "First brick mismatch")
+	require.Equal(t, models.Candle{Timestamp: 1001, Open: 110, High: 120, Low: 110, Close: 120}, candles[1], "Second brick mismatch")
+
+	// A reversal needs a brick size below the bottom of the last brick.
+	builder.ProcessTrades(barTrades(100, 90))
+	candles = builder.GetCandles()
+	require.Len(t, candles, 4, "Expected two down bricks")
+	require.Equal(t, models.Candle{Timestamp: 1000, Open: 110, High: 110, Low: 100, Close: 100, Volume: 626}, candles[2], "Reversal brick mismatch")
+	require.Equal(t, models.Candle{Timestamp: 1001, Open: 100, High: 100, Low: 90, Close: 90, Volume: 180}, candles[3], "Expected the down trend to continue")
+}
+
+func TestBarBuilder_RangeBars(t *testing.T) {
+	builder := NewBarBuilder(BarBuilderParams{Type: BarTypeRange, Size: 5})
+	builder.ProcessTrades(barTrades(100, 103, 97, 101, 99, 104))
+
+	candles := builder.GetCandles()
+	require.Len(t, candles, 3, "Expected two closed and a current bar")
+	// 97 would take the first bar beyond 5 so it starts the next one.
+	require.Equal(t, models.Candle{Timestamp: 1001, Open: 100, High: 103, Low: 100, Close: 103, Volume: 406}, candles[0], "First bar mismatch")
+	require.Equal(t, models.Candle{Timestamp: 1004, Open: 97, High: 101, Low: 97, Close: 99, Volume: 594}, candles[1], "Second bar mismatch")
+	require.Equal(t, 104.0, candles[2].Open, "Current bar mismatch")
+
+	// A bar spanning exactly Size closes on its last trade.
+	builder.ProcessTrades(barTrades(99))
+	require.Len(t, builder.GetCandles(), 3, "Expected the current bar to close")
+}
@@ -2,6 +2,9 @@ package logic
 
 import (
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/infinityCounter2/vh-trader/internal/models"
@@ -24,14 +27,113 @@ var BuilderIntervals = []BuilderInterval{
 	BuilderInterval1h,
 }
 
+const day = 24 * time.Hour
+
+// ParseBuilderInterval parses an interval like "1m", "4h" or "1d",
+// returning false if it isn't a positive whole number of seconds.
+func ParseBuilderInterval(k string) (BuilderInterval, bool) {
+	var intvl time.Duration
+	if days, ok := strings.CutSuffix(k, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, false
+		}
+		intvl = time.Duration(n) * day
+	} else {
+		var err error
+		if intvl, err = time.ParseDuration(k); err != nil {
+			return 0, false
+		}
+	}
+
+	if intvl <= 0 || intvl%time.Second != 0 {
+		return 0, false
+	}
+	return intvl, true
+}
+
+// FormatBuilderInterval formats the interval in its largest
+// whole unit, the inverse of ParseBuilderInterval.
+func FormatBuilderInterval(intvl BuilderInterval) string {
+	switch {
+	case intvl%day == 0:
+		return strconv.FormatInt(int64(intvl/day), 10) + "d"
+	case intvl%time.Hour == 0:
+		return strconv.FormatInt(int64(intvl/time.Hour), 10) + "h"
+	case intvl%time.Minute == 0:
+		return strconv.FormatInt(int64(intvl/time.Minute), 10) + "m"
+	}
+	return strconv.FormatInt(int64(intvl/time.Second), 10) + "s"
+}
+
+// LatePolicy determines how a CandleBuilder handles trades
+// belonging to a candle that has already closed.
+type LatePolicy int
+
+const (
+	// LatePolicyAccept amends closed candles with late trades that are
+	// within the allowed lateness. Trades beyond it are dropped.
+	LatePolicyAccept LatePolicy = iota
+	// LatePolicyReject drops every trade belonging to a closed candle.
+	LatePolicyReject
+	// LatePolicyCorrections amends closed candles with late trades that are
+	// within the allowed lateness. Trades beyond it are routed to the
+	// corrections log instead of altering any candle.
+	LatePolicyCorrections
+)
+
+var latePolicyNames = map[LatePolicy]string{
+	LatePolicyAccept:      "accept",
+	LatePolicyReject:      "reject",
+	LatePolicyCorrections: "corrections",
+}
+
+func (p LatePolicy) String() string {
+	return latePolicyNames[p]
+}
+
+// ParseLatePolicy returns the LatePolicy for the given name,
+// and false if there is no such policy.
+func ParseLatePolicy(name string) (LatePolicy, bool) {
+	for p, n := range latePolicyNames {
+		if n == name {
+			return p, true
+		}
+	}
+	return 0, false
+}
+
 type CandleBuilderParams struct {
 	Interval BuilderInterval
+
+	// LatePolicy is how trades for closed candles are handled.
+	//
+	// Defaults to LatePolicyAccept.
+	LatePolicy LatePolicy
+	// AllowedLateness is how far behind the watermark, the newest
+	// trade timestamp seen by the builder, a late trade may be
+	// and still amend a closed candle.
+	//
+	// Zero means late trades are never considered too late, though
+	// they never open a candle older than the first one.
+	AllowedLateness time.Duration
+}
+
+// LateStats are counters of the late trades seen by a CandleBuilder.
+type LateStats struct {
+	// Late is the number of trades that belonged to a closed candle.
+	Late int64
+	// Dropped is the number of late trades that were discarded.
+	Dropped int64
+	// Corrected is the number of late trades routed to the corrections log.
+	Corrected int64
 }
 
 // CandleBuilder is a structure that processes trades
 // to consturct Candle candles
 type CandleBuilder struct {
-	p CandleBuilderParams
+	p   CandleBuilderParams
+	mtx sync.RWMutex
 
 	current *models.Candle
 
@@ -41,16 +143,45 @@ type CandleBuilder struct {
 	// These should be routinely flushed
 	// to a in-memory kv store like redis
 	// and to a more persistent store like postgres.
-	// At that point a mutex should be added to protect this.
 	//
 	// Keyed by the timestamp of the candle.
 	closed map[int64]models.Candle
+
+	// trades contains the trades applied to each candle, in the
+	// order they were applied, so that a candle can be recomputed
+	// when one of its trades is cancelled or corrected. They are
+	// pruned once late trades can no longer amend the candle.
+	//
+	// Keyed by the timestamp of the candle.
+	trades map[int64][]models.Trade
+
+	// watermark is the newest trade timestamp(ms) processed.
+	watermark int64
+	// first is the timestamp of the oldest candle opened by a trade in
+	// order, late trades never open candles before it.
+	first     int64
+	lateStats LateStats
+	// corrections holds the late trades that were too late to amend
+	// a closed candle under LatePolicyCorrections.
+	//
+	// Like closed these should be flushed somewhere for review.
+	corrections []models.Trade
+
+	// amendments counts the changes to candles that were already
+	// closed, so that state derived from the closed candles, like
+	// indicators, knows when it has to be recomputed.
+	amendments uint64
+
+	// source is the finer builder the candles are rolled up
+	// from, when nil the builder consumes trades itself.
+	source *CandleBuilder
 }
 
 func NewBuilder(p CandleBuilderParams) *CandleBuilder {
 	return &CandleBuilder{
 		p:      p,
 		closed: make(map[int64]models.Candle),
+		trades: make(map[int64][]models.Trade),
 	}
 }
 
@@ -59,38 +190,443 @@ func NewBuilder(p CandleBuilderParams) *CandleBuilder {
 // The appropriate Candle candle is updated for each trade included. Candles
 // that do not exist at the time will be created.
 func (c *CandleBuilder) ProcessTrades(trades []models.Trade) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
 	for _, t := range trades {
 		c.processTrade(t)
 	}
 }
 
 func (c *CandleBuilder) processTrade(t models.Trade) {
-	exec := time.Unix(0, t.Timestamp*int64(time.Millisecond)).UTC()
-	tradeCandleTime := roundUpTime(exec, c.p.Interval)
+	tradeCandleTime := c.candleTime(t)
+
+	if t.Timestamp > c.watermark {
+		c.watermark = t.Timestamp
+	}
 
 	if c.current != nil && tradeCandleTime == c.current.Timestamp {
 		// This trade belongs in this candle.
 		updateCandle(c.current, t)
+		c.trades[tradeCandleTime] = append(c.trades[tradeCandleTime], t)
 	} else if c.current == nil || (c.current != nil && tradeCandleTime > c.current.Timestamp) {
 		// This is a new candle
 		candle := initializeCandle(tradeCandleTime, t)
 		if c.current != nil {
 			c.closed[c.current.Timestamp] = *c.current
+		} else if c.first == 0 || tradeCandleTime < c.first {
+			c.first = tradeCandleTime
 		}
 		c.current = candle
+		c.trades[tradeCandleTime] = append(c.trades[tradeCandleTime], t)
+		c.pruneTrades()
 	} else if tradeCandleTime < c.current.Timestamp {
-		// This is an old trade, really we should have a deep discussion
-		// on how to handle late trades before doing this but just update the old candle
-		// it may belong to.
-		candle, exists := c.closed[tradeCandleTime]
+		c.processLateTrade(t, tradeCandleTime)
+	}
+}
+
+// processLateTrade applies the LatePolicy to a trade
+// belonging to the already closed candle at candleTime.
+func (c *CandleBuilder) processLateTrade(t models.Trade, candleTime int64) {
+	c.lateStats.Late++
+
+	if c.p.LatePolicy == LatePolicyReject {
+		c.lateStats.Dropped++
+		return
+	}
+
+	candle, exists := c.closed[candleTime]
+	// Trades too far behind the watermark, or older than any candle
+	// even without a limit, don't touch the candles.
+	if (c.p.AllowedLateness > 0 && t.Timestamp < c.watermark-c.p.AllowedLateness.Milliseconds()) ||
+		(!exists && candleTime < c.first) {
+		if c.p.LatePolicy == LatePolicyCorrections {
+			c.lateStats.Corrected++
+			c.corrections = append(c.corrections, t)
+			return
+		}
+		c.lateStats.Dropped++
+		return
+	}
+
+	c.amendments++
+	if exists {
+		updateCandle(&candle, t)
+		candle.Revision++
+	} else {
+		// Build a new candle
+		candle = *(initializeCandle(candleTime, t))
+	}
+	c.closed[candleTime] = candle
+	c.trades[candleTime] = append(c.trades[candleTime], t)
+}
+
+// pruneTrades forgets the trades of the candles past the allowed
+// lateness, which late trades can no longer amend, so that trades
+// aren't kept forever. Their trades can no longer be cancelled or
+// corrected either, the candles are final unless rebuilt.
+func (c *CandleBuilder) pruneTrades() {
+	if c.p.AllowedLateness <= 0 {
+		return
+	}
+
+	// The trades of a candle are older than its close.
+	horizon := c.watermark - c.p.AllowedLateness.Milliseconds()
+	for ts := range c.trades {
+		if ts <= horizon {
+			delete(c.trades, ts)
+		}
+	}
+}
+
+// CancelTrade reverses the effect of a previously processed trade by
+// removing it from its candle and recomputing that candle.
+//
+// It returns false if the trade was never applied to a candle.
+func (c *CandleBuilder) CancelTrade(t models.Trade) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	candleTime := c.candleTime(t)
+	if !c.removeTrade(candleTime, t.TradeID) {
+		return false
+	}
+
+	c.recomputeCandle(candleTime)
+	return true
+}
+
+// CorrectTrade replaces a previously processed trade with its amended
+// version and recomputes the affected candles. The amended trade
+// may belong to a different candle than the original.
+//
+// Corrections bypass the LatePolicy since the original trade was
+// already accepted. It returns false, leaving the candles unchanged, if
+// the original trade was never applied to a candle, or if the amended
+// trade belongs to a candle whose trades were pruned, as that candle
+// can no longer be recomputed from its trades.
+func (c *CandleBuilder) CorrectTrade(original, amended models.Trade) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	originalTime := c.candleTime(original)
+	amendedTime := c.candleTime(amended)
+
+	if originalTime == amendedTime {
+		trades := c.trades[originalTime]
+		for i := range trades {
+			if trades[i].TradeID == original.TradeID {
+				// Keep the position so the candle is recomputed
+				// in the same order as the trades were processed.
+				trades[i] = amended
+				c.recomputeCandle(originalTime)
+				return true
+			}
+		}
+		return false
+	}
+
+	if c.hasCandle(amendedTime) && len(c.trades[amendedTime]) == 0 {
+		return false
+	}
+	if !c.removeTrade(originalTime, original.TradeID) {
+		return false
+	}
+	c.trades[amendedTime] = append(c.trades[amendedTime], amended)
+
+	c.recomputeCandle(originalTime)
+	c.recomputeCandle(amendedTime)
+	return true
+}
+
+// hasCandle reports whether there is a candle at candleTime.
+func (c *CandleBuilder) hasCandle(candleTime int64) bool {
+	if c.current != nil && c.current.Timestamp == candleTime {
+		return true
+	}
+	_, exists := c.closed[candleTime]
+	return exists
+}
+
+// removeTrade removes the trade with the given ID from the
+// trades of the candle at candleTime.
+func (c *CandleBuilder) removeTrade(candleTime int64, tradeID string) bool {
+	trades := c.trades[candleTime]
+	for i := range trades {
+		if trades[i].TradeID == tradeID {
+			c.trades[candleTime] = append(trades[:i], trades[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// recomputeCandle rebuilds the candle at candleTime from its trades.
+//
+// Amending a closed candle increments its revision. If the current
+// candle is left without trades the newest closed candle becomes
+// the current one.
+func (c *CandleBuilder) recomputeCandle(candleTime int64) {
+	trades := c.trades[candleTime]
+
+	var candle *models.Candle
+	for _, t := range trades {
+		if candle == nil {
+			candle = initializeCandle(candleTime, t)
+			continue
+		}
+		updateCandle(candle, t)
+	}
+
+	if c.current == nil || candleTime > c.current.Timestamp {
+		// The candle is newer than the current one.
+		if candle == nil {
+			return
+		}
+		if c.current != nil {
+			c.closed[c.current.Timestamp] = *c.current
+		}
+		c.current = candle
+		return
+	}
+
+	if candleTime == c.current.Timestamp {
+		if candle != nil {
+			c.current = candle
+			return
+		}
+
+		delete(c.trades, candleTime)
+		c.current = nil
+		c.promoteNewestClosed()
+		return
+	}
+
+	c.amendments++
+	if candle == nil {
+		delete(c.trades, candleTime)
+		delete(c.closed, candleTime)
+		return
+	}
+
+	candle.Revision = c.closed[candleTime].Revision + 1
+	c.closed[candleTime] = *candle
+}
+
+// promoteNewestClosed makes the newest closed candle the current one.
+func (c *CandleBuilder) promoteNewestClosed() {
+	found := false
+	var newest int64
+	for ts := range c.closed {
+		if !found || ts > newest {
+			newest = ts
+			found = true
+		}
+	}
+	if !found {
+		return
+	}
+
+	candle := c.closed[newest]
+	delete(c.closed, newest)
+	c.current = &candle
+	c.amendments++
+}
+
+// rollUp recomputes the candles containing the source candles closing
+// at the given timestamps(ms) by aggregating the source candles.
+//
+// A closed candle is only amended, bumping its revision, when the
+// aggregate differs from it.
+func (c *CandleBuilder) rollUp(sourceTimes []int64) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.source.mtx.RLock()
+	defer c.source.mtx.RUnlock()
+
+	step := c.source.p.Interval.Milliseconds()
+	done := make(map[int64]bool)
+	for _, ts := range sourceTimes {
+		// The source candle covers the step before its close.
+		candleTime := roundUpTime(time.UnixMilli(ts-step).UTC(), c.p.Interval)
+		if done[candleTime] {
+			continue
+		}
+		done[candleTime] = true
+
+		c.setCandle(candleTime, c.source.aggregate(candleTime-c.p.Interval.Milliseconds(), candleTime))
+	}
+}
+
+// aggregate returns the candle made of the candles closing
+// after from and up to to, or nil if there are none.
+func (c *CandleBuilder) aggregate(from, to int64) *models.Candle {
+	var candle *models.Candle
+	for ts := from + c.p.Interval.Milliseconds(); ts <= to; ts += c.p.Interval.Milliseconds() {
+		part, exists := c.closed[ts]
+		if c.current != nil && c.current.Timestamp == ts {
+			part, exists = *c.current, true
+		}
+		if !exists {
+			continue
+		}
+
+		if candle == nil {
+			candle = &models.Candle{Timestamp: to, Open: part.Open, High: part.High, Low: part.Low}
+		}
+		candle.High = max(candle.High, part.High)
+		candle.Low = min(candle.Low, part.Low)
+		candle.Close = part.Close
+		candle.Volume += part.Volume
+	}
+	return candle
+}
+
+// setCandle replaces the candle at candleTime, removing it when nil.
+func (c *CandleBuilder) setCandle(candleTime int64, candle *models.Candle) {
+	if c.current == nil || candleTime > c.current.Timestamp {
+		// The candle is newer than the current one.
+		if candle == nil {
+			return
+		}
+		if c.current != nil {
+			c.closed[c.current.Timestamp] = *c.current
+		}
+		c.current = candle
+		return
+	}
+
+	if candleTime == c.current.Timestamp {
+		if candle != nil {
+			c.current = candle
+			return
+		}
+		c.current = nil
+		c.promoteNewestClosed()
+		return
+	}
+
+	existing, exists := c.closed[candleTime]
+	if candle == nil {
 		if exists {
-			updateCandle(&candle, t)
-		} else {
-			// Build a new candle
-			candle = *(initializeCandle(tradeCandleTime, t))
+			delete(c.closed, candleTime)
+			c.amendments++
+		}
+		return
+	}
+
+	if exists {
+		candle.Revision = existing.Revision
+		if *candle == existing {
+			return
 		}
-		c.closed[tradeCandleTime] = candle
+		candle.Revision++
 	}
+	c.closed[candleTime] = *candle
+	c.amendments++
+}
+
+// replaceCandles replaces the candles closing after from and up to to(ms)
+// with the candles of the other builder, along with their trades.
+//
+// A replaced candle keeps its revision when it's unchanged and
+// has it bumped otherwise. The caller must hold both locks.
+func (c *CandleBuilder) replaceCandles(o *CandleBuilder, from, to int64) {
+	inRange := func(ts int64) bool {
+		return ts > from && ts <= to
+	}
+
+	// Treat the current candle as closed until the
+	// newest candle is promoted once done.
+	if c.current != nil {
+		c.closed[c.current.Timestamp] = *c.current
+		c.current = nil
+	}
+
+	old := make(map[int64]models.Candle)
+	for ts, candle := range c.closed {
+		if inRange(ts) {
+			old[ts] = candle
+			delete(c.closed, ts)
+			delete(c.trades, ts)
+		}
+	}
+
+	candles := make([]models.Candle, 0, len(o.closed)+1)
+	for _, candle := range o.closed {
+		candles = append(candles, candle)
+	}
+	if o.current != nil {
+		candles = append(candles, *o.current)
+	}
+	for _, candle := range candles {
+		if !inRange(candle.Timestamp) {
+			continue
+		}
+
+		candle.Revision = 0
+		if existing, exists := old[candle.Timestamp]; exists {
+			candle.Revision = existing.Revision
+			if candle != existing {
+				candle.Revision++
+			}
+		}
+		c.closed[candle.Timestamp] = candle
+		if trades := o.trades[candle.Timestamp]; len(trades) > 0 {
+			c.trades[candle.Timestamp] = append([]models.Trade{}, trades...)
+		}
+	}
+
+	c.watermark = max(c.watermark, o.watermark)
+	if o.first != 0 && (c.first == 0 || o.first < c.first) {
+		c.first = o.first
+	}
+	c.promoteNewestClosed()
+	c.amendments++
+}
+
+// candleTime returns the timestamp of the candle the trade belongs to.
+func (c *CandleBuilder) candleTime(t models.Trade) int64 {
+	exec := time.Unix(0, t.Timestamp*int64(time.Millisecond)).UTC()
+	return roundUpTime(exec, c.p.Interval)
+}
+
+// LateStats returns the late trade counters of the builder. Builders
+// rolled up from a finer builder return the counters of that builder,
+// as it decides which late trades are applied.
+func (c *CandleBuilder) LateStats() LateStats {
+	if c.source != nil {
+		return c.source.LateStats()
+	}
+
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	return c.lateStats
+}
+
+// Corrections returns a copy of the corrections log, in the
+// order the trades were received. Like LateStats, builders rolled
+// up from a finer builder return the log of that builder.
+func (c *CandleBuilder) Corrections() []models.Trade {
+	if c.source != nil {
+		return c.source.Corrections()
+	}
+
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	corrections := make([]models.Trade, len(c.corrections))
+	copy(corrections, c.corrections)
+	return corrections
+}
+
+// HasCandles reports whether the builder has built any candle.
+func (c *CandleBuilder) HasCandles() bool {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	return c.current != nil
 }
 
 // GetCandles returns all the candles in the builder including the closed ones.
@@ -98,6 +634,9 @@ func (c *CandleBuilder) processTrade(t models.Trade) {
 // to fetch closed candles, and then that's used by some API/data layer instead
 // of having this GetCandles method.
 func (c *CandleBuilder) GetCandles() models.CandleList {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
 	if c.current == nil && len(c.closed) == 0 {
 		return nil
 	}
@@ -107,7 +646,9 @@ func (c *CandleBuilder) GetCandles() models.CandleList {
 		candles = append(candles, c)
 	}
 
-	candles = append(candles, *c.current)
+	if c.current != nil {
+		candles = append(candles, *c.current)
+	}
 
 	// Sort candles in Chronological order.
 	sort.Slice(candles, func(i, j int) bool {
@@ -117,6 +658,94 @@ func (c *CandleBuilder) GetCandles() models.CandleList {
 	return models.CandleList(candles)
 }
 
+// CandlesSince returns the candles closing after the given
+// timestamp(ms), sorted in chronological order.
+func (c *CandleBuilder) CandlesSince(ts int64) models.CandleList {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	return c.candlesSince(ts)
+}
+
+// Series returns the closed candles closing after the given timestamp(ms)
+// sorted in chronological order, a copy of the current candle, and the
+// number of times closed candles have been amended.
+//
+// Candles closing after ts are only appended to the series while the
+// amendments stay the same, otherwise the whole series must be reread.
+func (c *CandleBuilder) Series(ts int64) (models.CandleList, *models.Candle, uint64) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	if c.current == nil {
+		return models.CandleList{}, nil, c.amendments
+	}
+
+	// Closed candles are looked up from the one after ts.
+	candles := c.candlesSince(ts + 1)
+	if len(candles) == 0 {
+		return models.CandleList{}, nil, c.amendments
+	}
+	current := candles[len(candles)-1]
+	return candles[:len(candles)-1], &current, c.amendments
+}
+
+// seriesCursor tracks how far a series derived from
+// the candles of a CandleBuilder has been computed.
+type seriesCursor struct {
+	builder    *CandleBuilder
+	lastClosed int64
+	amendments uint64
+}
+
+// next returns the closed candles of the builder that were not returned
+// yet and its current candle. When a closed candle was amended, or the
+// builder changed, every candle is returned again and reset is set so
+// the derived series is computed from scratch.
+func (s *seriesCursor) next(b *CandleBuilder) (closed models.CandleList, current *models.Candle, reset bool) {
+	closed, current, amendments := b.Series(s.lastClosed)
+	if b != s.builder || amendments != s.amendments {
+		reset = true
+		s.builder = b
+		closed, current, s.amendments = b.Series(0)
+	}
+
+	if len(closed) > 0 {
+		s.lastClosed = closed[len(closed)-1].Timestamp
+	}
+	return closed, current, reset
+}
+
+func (c *CandleBuilder) candlesSince(ts int64) models.CandleList {
+	if c.current == nil || c.current.Timestamp <= ts {
+		return models.CandleList{}
+	}
+
+	step := c.p.Interval.Milliseconds()
+	first := roundUpTime(time.UnixMilli(ts).UTC(), c.p.Interval)
+
+	var candles models.CandleList
+	if (c.current.Timestamp-first)/step > int64(len(c.closed)) {
+		// Fewer closed candles than buckets in the range.
+		for ts, candle := range c.closed {
+			if ts >= first {
+				candles = append(candles, candle)
+			}
+		}
+		sort.Slice(candles, func(i, j int) bool {
+			return candles[i].Timestamp < candles[j].Timestamp
+		})
+	} else {
+		for ts := first; ts < c.current.Timestamp; ts += step {
+			if candle, exists := c.closed[ts]; exists {
+				candles = append(candles, candle)
+			}
+		}
+	}
+
+	return append(candles, *c.current)
+}
+
 func initializeCandle(candleTime int64, t models.Trade) *models.Candle {
 	candle := &models.Candle{
 		Timestamp: candleTime,
@@ -26,7 +26,7 @@ func TestProcessTrade_NewCandle(t *testing.T) {
 	tradeTime := time.Date(2023, 1, 1, 10, 0, 30, 0, time.UTC)
 	trade := models.Trade{
 		TradeID:   "1",
-		Timestamp: tradeTime.Unix(),
+		Timestamp: tradeTime.UnixMilli(),
 		Price:     100.0,
 		Size:      1.0,
 	}
@@ -50,15 +50,15 @@ func TestProcessTrade_SameCandle(t *testing.T) {
 	builder := NewBuilder(params)
 
 	tradeTime1 := time.Date(2023, 1, 1, 10, 0, 10, 0, time.UTC)
-	trade1 := models.Trade{TradeID: "1", Timestamp: tradeTime1.Unix(), Price: 100.0, Size: 1.0}
+	trade1 := models.Trade{TradeID: "1", Timestamp: tradeTime1.UnixMilli(), Price: 100.0, Size: 1.0}
 	builder.processTrade(trade1)
 
 	tradeTime2 := time.Date(2023, 1, 1, 10, 0, 20, 0, time.UTC)
-	trade2 := models.Trade{TradeID: "2", Timestamp: tradeTime2.Unix(), Price: 105.0, Size: 2.0}
+	trade2 := models.Trade{TradeID: "2", Timestamp: tradeTime2.UnixMilli(), Price: 105.0, Size: 2.0}
 	builder.processTrade(trade2)
 
 	tradeTime3 := time.Date(2023, 1, 1, 10, 0, 40, 0, time.UTC)
-	trade3 := models.Trade{TradeID: "3", Timestamp: tradeTime3.Unix(), Price: 95.0, Size: 3.0}
+	trade3 := models.Trade{TradeID: "3", Timestamp: tradeTime3.UnixMilli(), Price: 95.0, Size: 3.0}
 	builder.processTrade(trade3)
 
 	require.NotNil(t, builder.current, "Expected current candle to be initialized")
@@ -80,11 +80,11 @@ func TestProcessTrade_NewInterval(t *testing.T) {
 	builder := NewBuilder(params)
 
 	tradeTime1 := time.Date(2023, 1, 1, 10, 0, 10, 0, time.UTC)
-	trade1 := models.Trade{TradeID: "1", Timestamp: tradeTime1.Unix(), Price: 100.0, Size: 1.0}
+	trade1 := models.Trade{TradeID: "1", Timestamp: tradeTime1.UnixMilli(), Price: 100.0, Size: 1.0}
 	builder.processTrade(trade1)
 
 	tradeTime2 := time.Date(2023, 1, 1, 10, 1, 0, 0, time.UTC) // New minute
-	trade2 := models.Trade{TradeID: "2", Timestamp: tradeTime2.Unix(), Price: 110.0, Size: 2.0}
+	trade2 := models.Trade{TradeID: "2", Timestamp: tradeTime2.UnixMilli(), Price: 110.0, Size: 2.0}
 	builder.processTrade(trade2)
 
 	require.NotNil(t, builder.current, "Expected current candle to be initialized")
@@ -116,17 +116,17 @@ func TestProcessTrade_LateTrade(t *testing.T) {
 
 	// First trade, creates a candle at 10:01
 	tradeTime1 := time.Date(2023, 1, 1, 10, 0, 30, 0, time.UTC) // This will round up to 10:01
-	trade1 := models.Trade{TradeID: "1", Timestamp: tradeTime1.Unix(), Price: 100.0, Size: 1.0}
+	trade1 := models.Trade{TradeID: "1", Timestamp: tradeTime1.UnixMilli(), Price: 100.0, Size: 1.0}
 	builder.processTrade(trade1)
 
 	// Second trade, creates a candle at 10:02 and closes the 10:01 candle
 	tradeTime2 := time.Date(2023, 1, 1, 10, 1, 30, 0, time.UTC) // This will round up to 10:02
-	trade2 := models.Trade{TradeID: "2", Timestamp: tradeTime2.Unix(), Price: 120.0, Size: 2.0}
+	trade2 := models.Trade{TradeID: "2", Timestamp: tradeTime2.UnixMilli(), Price: 120.0, Size: 2.0}
 	builder.processTrade(trade2)
 
 	// Late trade for the 10:01 candle
 	lateTradeTime := time.Date(2023, 1, 1, 10, 0, 45, 0, time.UTC) // Still belongs to 10:01 candle
-	lateTrade := models.Trade{TradeID: "3", Timestamp: lateTradeTime.Unix(), Price: 90.0, Size: 0.5}
+	lateTrade := models.Trade{TradeID: "3", Timestamp: lateTradeTime.UnixMilli(), Price: 90.0, Size: 0.5}
 	builder.processTrade(lateTrade)
 
 	// Check the updated 10:01 closed candle
@@ -154,10 +154,10 @@ func TestProcessTrades_MultipleTrades(t *testing.T) {
 	builder := NewBuilder(params)
 
 	trades := []models.Trade{
-		{TradeID: "1", Timestamp: time.Date(2023, 1, 1, 10, 0, 10, 0, time.UTC).Unix(), Price: 100.0, Size: 1.0},
-		{TradeID: "2", Timestamp: time.Date(2023, 1, 1, 10, 0, 20, 0, time.UTC).Unix(), Price: 105.0, Size: 2.0},
-		{TradeID: "3", Timestamp: time.Date(2023, 1, 1, 10, 1, 5, 0, time.UTC).Unix(), Price: 110.0, Size: 1.5},
-		{TradeID: "4", Timestamp: time.Date(2023, 1, 1, 10, 1, 15, 0, time.UTC).Unix(), Price: 108.0, Size: 0.5},
+		{TradeID: "1", Timestamp: time.Date(2023, 1, 1, 10, 0, 10, 0, time.UTC).UnixMilli(), Price: 100.0, Size: 1.0},
+		{TradeID: "2", Timestamp: time.Date(2023, 1, 1, 10, 0, 20, 0, time.UTC).UnixMilli(), Price: 105.0, Size: 2.0},
+		{TradeID: "3", Timestamp: time.Date(2023, 1, 1, 10, 1, 5, 0, time.UTC).UnixMilli(), Price: 110.0, Size: 1.5},
+		{TradeID: "4", Timestamp: time.Date(2023, 1, 1, 10, 1, 15, 0, time.UTC).UnixMilli(), Price: 108.0, Size: 0.5},
 	}
 
 	builder.ProcessTrades(trades)
@@ -185,7 +185,7 @@ func TestProcessTrades_MultipleTrades(t *testing.T) {
 
 func TestInitializeCandle(t *testing.T) {
 	tradeTime := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
-	trade := models.Trade{Timestamp: tradeTime.Unix(), Price: 100.0, Size: 1.0}
+	trade := models.Trade{Timestamp: tradeTime.UnixMilli(), Price: 100.0, Size: 1.0}
 	candleTime := roundUpTime(tradeTime, BuilderInterval1m)
 
 	candle := initializeCandle(candleTime, trade)
@@ -253,31 +253,31 @@ func TestRoundUpTime(t *testing.T) {
 			name:     "Round up to next 1-minute",
 			input:    time.Date(2023, 1, 1, 10, 0, 59, 999, time.UTC),
 			interval: BuilderInterval1m,
-			expected: time.Date(2023, 1, 1, 10, 1, 0, 0, time.UTC).Unix(),
+			expected: time.Date(2023, 1, 1, 10, 1, 0, 0, time.UTC).UnixMilli(),
 		},
 		{
 			name:     "Round up to next 1-minute, already aligned",
 			input:    time.Date(2023, 1, 1, 10, 0, 30, 0, time.UTC),
 			interval: BuilderInterval1m,
-			expected: time.Date(2023, 1, 1, 10, 1, 0, 0, time.UTC).Unix(),
+			expected: time.Date(2023, 1, 1, 10, 1, 0, 0, time.UTC).UnixMilli(),
 		},
 		{
 			name:     "Round up to next 5-minute",
 			input:    time.Date(2023, 1, 1, 10, 2, 30, 0, time.UTC),
 			interval: BuilderInterval5m,
-			expected: time.Date(2023, 1, 1, 10, 5, 0, 0, time.UTC).Unix(),
+			expected: time.Date(2023, 1, 1, 10, 5, 0, 0, time.UTC).UnixMilli(),
 		},
 		{
 			name:     "Zero duration",
 			input:    time.Date(2023, 1, 1, 10, 0, 30, 0, time.UTC),
 			interval: 0,
-			expected: time.Date(2023, 1, 1, 10, 0, 30, 0, time.UTC).Unix(),
+			expected: time.Date(2023, 1, 1, 10, 0, 30, 0, time.UTC).UnixMilli(),
 		},
 		{
 			name:     "Round up to next 1-hour",
 			input:    time.Date(2023, 1, 1, 10, 30, 0, 0, time.UTC),
 			interval: BuilderInterval1h,
-			expected: time.Date(2023, 1, 1, 11, 0, 0, 0, time.UTC).Unix(),
+			expected: time.Date(2023, 1, 1, 11, 0, 0, 0, time.UTC).UnixMilli(),
 		},
 	}
 
@@ -287,6 +287,34 @@ func TestRoundUpTime(t *testing.T) {
 	}
 }
 
+func TestParseBuilderInterval(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected BuilderInterval
+		ok       bool
+	}{
+		{input: "1m", expected: BuilderInterval1m, ok: true},
+		{input: "15m", expected: BuilderInterval15m, ok: true},
+		{input: "4h", expected: 4 * time.Hour, ok: true},
+		{input: "1d", expected: 24 * time.Hour, ok: true},
+		{input: "30s", expected: 30 * time.Second, ok: true},
+		{input: "0m"},
+		{input: "-1h"},
+		{input: "1.5s"},
+		{input: "xd"},
+		{input: ""},
+	}
+
+	for _, tc := range testCases {
+		got, ok := ParseBuilderInterval(tc.input)
+		require.Equalf(t, tc.ok, ok, "Unexpected result parsing %q", tc.input)
+		require.Equalf(t, tc.expected, got, "Unexpected interval parsing %q", tc.input)
+		if ok {
+			require.Equal(t, tc.input, FormatBuilderInterval(got), "Expected formatting to round trip")
+		}
+	}
+}
+
 func TestBuilder_InitialClosedMap(t *testing.T) {
 	params := CandleBuilderParams{Interval: BuilderInterval1m}
 	builder := NewBuilder(params)
@@ -300,17 +328,17 @@ func TestProcessTrades(t *testing.T) {
 	builder := NewBuilder(params)
 
 	trades := []models.Trade{
-		{TradeID: "1", Timestamp: time.Date(2023, 1, 1, 10, 0, 15, 0, time.UTC).Unix(), Price: 100, Size: 1},
-		{TradeID: "2", Timestamp: time.Date(2023, 1, 1, 10, 0, 45, 0, time.UTC).Unix(), Price: 105, Size: 2},
-		{TradeID: "3", Timestamp: time.Date(2023, 1, 1, 10, 1, 10, 0, time.UTC).Unix(), Price: 110, Size: 1},
+		{TradeID: "1", Timestamp: time.Date(2023, 1, 1, 10, 0, 15, 0, time.UTC).UnixMilli(), Price: 100, Size: 1},
+		{TradeID: "2", Timestamp: time.Date(2023, 1, 1, 10, 0, 45, 0, time.UTC).UnixMilli(), Price: 105, Size: 2},
+		{TradeID: "3", Timestamp: time.Date(2023, 1, 1, 10, 1, 10, 0, time.UTC).UnixMilli(), Price: 110, Size: 1},
 	}
 
 	builder.ProcessTrades(trades)
 
 	// Check the first closed candle (10:01)
-	expectedClosedTimestamp1 := time.Date(2023, 1, 1, 10, 1, 0, 0, time.UTC).Unix()
+	expectedClosedTimestamp1 := time.Date(2023, 1, 1, 10, 1, 0, 0, time.UTC).UnixMilli()
 	_, ok := builder.closed[expectedClosedTimestamp1]
-	require.True(t, ok, "Expected closed candle for %v", time.Unix(expectedClosedTimestamp1, 0))
+	require.True(t, ok, "Expected closed candle for %v", time.UnixMilli(expectedClosedTimestamp1))
 	closedCandle := builder.closed[expectedClosedTimestamp1]
 	require.Equal(t, float64(100), closedCandle.Open, "Closed candle (10:01) Open incorrect")
 	require.Equal(t, float64(105), closedCandle.High, "Closed candle (10:01) High incorrect")
@@ -319,7 +347,7 @@ func TestProcessTrades(t *testing.T) {
 	require.Equal(t, float64(100*1+105*2), closedCandle.Volume, "Closed candle (10:01) Volume incorrect")
 
 	// Check the current candle (10:02)
-	expectedCurrentTimestamp := time.Date(2023, 1, 1, 10, 2, 0, 0, time.UTC).Unix()
+	expectedCurrentTimestamp := time.Date(2023, 1, 1, 10, 2, 0, 0, time.UTC).UnixMilli()
 	require.NotNil(t, builder.current, "Expected current candle to be initialized")
 	require.Equal(t, expectedCurrentTimestamp, builder.current.Timestamp, "Current candle (10:02) Timestamp incorrect")
 	require.Equal(t, float64(110), builder.current.Open, "Current candle (10:02) Open incorrect")
@@ -328,3 +356,224 @@ func TestProcessTrades(t *testing.T) {
 	require.Equal(t, float64(110), builder.current.Close, "Current candle (10:02) Close incorrect")
 	require.Equal(t, float64(110*1), builder.current.Volume, "Current candle (10:02) Volume incorrect")
 }
+
+func TestProcessTrade_LatePolicy(t *testing.T) {
+	base := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	at := func(offset time.Duration) int64 {
+		return base.Add(offset).UnixMilli()
+	}
+
+	testCases := []struct {
+		name              string
+		params            CandleBuilderParams
+		lateOffset        time.Duration
+		expectedStats     LateStats
+		expectedRevision  int64
+		expectedVolume    float64
+		expectedCorrected int
+	}{
+		{
+			name:             "Accept within lateness",
+			params:           CandleBuilderParams{Interval: BuilderInterval1m, AllowedLateness: 5 * time.Minute},
+			lateOffset:       30 * time.Second,
+			expectedStats:    LateStats{Late: 1},
+			expectedRevision: 1,
+			expectedVolume:   100 + 50,
+		},
+		{
+			name:           "Accept beyond lateness",
+			params:         CandleBuilderParams{Interval: BuilderInterval1m, AllowedLateness: 5 * time.Second},
+			lateOffset:     30 * time.Second,
+			expectedStats:  LateStats{Late: 1, Dropped: 1},
+			expectedVolume: 100,
+		},
+		{
+			name:           "Reject",
+			params:         CandleBuilderParams{Interval: BuilderInterval1m, LatePolicy: LatePolicyReject},
+			lateOffset:     30 * time.Second,
+			expectedStats:  LateStats{Late: 1, Dropped: 1},
+			expectedVolume: 100,
+		},
+		{
+			name:              "Corrections beyond lateness",
+			params:            CandleBuilderParams{Interval: BuilderInterval1m, LatePolicy: LatePolicyCorrections, AllowedLateness: 5 * time.Second},
+			lateOffset:        30 * time.Second,
+			expectedStats:     LateStats{Late: 1, Corrected: 1},
+			expectedVolume:    100,
+			expectedCorrected: 1,
+		},
+		{
+			name:           "Accept without limit before the first candle",
+			params:         CandleBuilderParams{Interval: BuilderInterval1m},
+			lateOffset:     -24 * time.Hour,
+			expectedStats:  LateStats{Late: 1, Dropped: 1},
+			expectedVolume: 100,
+		},
+		{
+			name:              "Corrections without limit before the first candle",
+			params:            CandleBuilderParams{Interval: BuilderInterval1m, LatePolicy: LatePolicyCorrections},
+			lateOffset:        -24 * time.Hour,
+			expectedStats:     LateStats{Late: 1, Corrected: 1},
+			expectedVolume:    100,
+			expectedCorrected: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		builder := NewBuilder(tc.params)
+		builder.ProcessTrades([]models.Trade{
+			{TradeID: "1", Timestamp: at(10 * time.Second), Price: 100, Size: 1},
+			{TradeID: "2", Timestamp: at(70 * time.Second), Price: 110, Size: 1},
+			{TradeID: "3", Timestamp: at(tc.lateOffset), Price: 50, Size: 1},
+		})
+
+		closedCandle, exists := builder.closed[at(time.Minute)]
+		require.Truef(t, exists, "%s: expected closed candle", tc.name)
+		require.Equalf(t, tc.expectedStats, builder.LateStats(), "%s: stats mismatch", tc.name)
+		require.Equalf(t, tc.expectedRevision, closedCandle.Revision, "%s: revision mismatch", tc.name)
+		require.Equalf(t, tc.expectedVolume, closedCandle.Volume, "%s: volume mismatch", tc.name)
+		require.Lenf(t, builder.Corrections(), tc.expectedCorrected, "%s: corrections mismatch", tc.name)
+		require.Lenf(t, builder.closed, 1, "%s: expected late trades not to open candles", tc.name)
+	}
+}
+
+func TestParseLatePolicy(t *testing.T) {
+	for _, p := range []LatePolicy{LatePolicyAccept, LatePolicyReject, LatePolicyCorrections} {
+		parsed, ok := ParseLatePolicy(p.String())
+		require.True(t, ok, "Expected %q to parse", p)
+		require.Equal(t, p, parsed, "Policy mismatch")
+	}
+
+	_, ok := ParseLatePolicy("ignore")
+	require.False(t, ok, "Expected unknown policy to fail parsing")
+}
+
+func TestCancelTrade(t *testing.T) {
+	builder := NewBuilder(CandleBuilderParams{Interval: BuilderInterval1m})
+
+	trades := []models.Trade{
+		{TradeID: "1", Timestamp: time.Date(2023, 1, 1, 10, 0, 10, 0, time.UTC).UnixMilli(), Price: 100, Size: 1},
+		{TradeID: "2", Timestamp: time.Date(2023, 1, 1, 10, 0, 20, 0, time.UTC).UnixMilli(), Price: 120, Size: 1},
+		{TradeID: "3", Timestamp: time.Date(2023, 1, 1, 10, 1, 10, 0, time.UTC).UnixMilli(), Price: 110, Size: 1},
+	}
+	builder.ProcessTrades(trades)
+
+	require.True(t, builder.CancelTrade(trades[1]), "Expected trade 2 to be cancelled")
+	require.False(t, builder.CancelTrade(trades[1]), "Expected trade 2 to be cancelled only once")
+
+	closedCandle := builder.closed[time.Date(2023, 1, 1, 10, 1, 0, 0, time.UTC).UnixMilli()]
+	require.Equal(t, 100.0, closedCandle.High, "Closed candle High not recomputed")
+	require.Equal(t, 100.0, closedCandle.Close, "Closed candle Close not recomputed")
+	require.Equal(t, 100.0, closedCandle.Volume, "Closed candle Volume not recomputed")
+	require.Equal(t, int64(1), closedCandle.Revision, "Closed candle Revision not incremented")
+
+	// Cancelling the only trade of the current candle makes
+	// the newest closed candle current again.
+	require.True(t, builder.CancelTrade(trades[2]), "Expected trade 3 to be cancelled")
+	require.NotNil(t, builder.current, "Expected current candle")
+	require.Equal(t, closedCandle.Timestamp, builder.current.Timestamp, "Expected closed candle to become current")
+	require.Empty(t, builder.closed, "Expected no closed candles")
+	require.Len(t, builder.GetCandles(), 1, "Expected a single candle")
+}
+
+func TestPruneTrades(t *testing.T) {
+	builder := NewBuilder(CandleBuilderParams{Interval: BuilderInterval1m, AllowedLateness: 5 * time.Minute})
+
+	base := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	trades := []models.Trade{
+		{TradeID: "1", Timestamp: base.Add(10 * time.Second).UnixMilli(), Price: 100, Size: 1},
+		{TradeID: "2", Timestamp: base.Add(3 * time.Minute).UnixMilli(), Price: 110, Size: 1},
+		{TradeID: "3", Timestamp: base.Add(7 * time.Minute).UnixMilli(), Price: 120, Size: 1},
+	}
+	builder.ProcessTrades(trades)
+
+	require.Len(t, builder.trades, 2, "Expected the trades of candles past the allowed lateness to be pruned")
+	require.False(t, builder.CancelTrade(trades[0]), "Expected pruned trades not to be cancelled")
+	require.True(t, builder.CancelTrade(trades[1]), "Expected trades within the allowed lateness to be cancelled")
+	require.Len(t, builder.GetCandles(), 2, "Expected the candles to be kept")
+}
+
+func TestCorrectTrade_IntoPrunedCandle(t *testing.T) {
+	builder := NewBuilder(CandleBuilderParams{Interval: BuilderInterval1m, AllowedLateness: 5 * time.Minute})
+
+	base := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	trades := []models.Trade{
+		{TradeID: "1", Timestamp: base.Add(10 * time.Second).UnixMilli(), Price: 100, Size: 2},
+		{TradeID: "2", Timestamp: base.Add(7 * time.Minute).UnixMilli(), Price: 110, Size: 1},
+		{TradeID: "3", Timestamp: base.Add(8 * time.Minute).UnixMilli(), Price: 120, Size: 1},
+	}
+	builder.ProcessTrades(trades)
+	before := builder.GetCandles()
+
+	moved := trades[1]
+	moved.Timestamp = base.Add(20 * time.Second).UnixMilli()
+	require.False(t, builder.CorrectTrade(trades[1], moved), "Expected a correction into a pruned candle to fail")
+	require.Equal(t, before, builder.GetCandles(), "Expected the candles to be unchanged")
+	require.True(t, builder.CancelTrade(trades[1]), "Expected the original trade to be kept")
+}
+
+func TestCorrectTrade(t *testing.T) {
+	builder := NewBuilder(CandleBuilderParams{Interval: BuilderInterval1m})
+
+	trades := []models.Trade{
+		{TradeID: "1", Timestamp: time.Date(2023, 1, 1, 10, 0, 10, 0, time.UTC).UnixMilli(), Price: 100, Size: 1},
+		{TradeID: "2", Timestamp: time.Date(2023, 1, 1, 10, 0, 20, 0, time.UTC).UnixMilli(), Price: 120, Size: 1},
+		{TradeID: "3", Timestamp: time.Date(2023, 1, 1, 10, 1, 10, 0, time.UTC).UnixMilli(), Price: 110, Size: 1},
+	}
+	builder.ProcessTrades(trades)
+
+	// Amend the price in place.
+	amended := trades[0]
+	amended.Price = 90
+	require.True(t, builder.CorrectTrade(trades[0], amended), "Expected trade 1 to be corrected")
+
+	closedTime := time.Date(2023, 1, 1, 10, 1, 0, 0, time.UTC).UnixMilli()
+	closedCandle := builder.closed[closedTime]
+	require.Equal(t, 90.0, closedCandle.Open, "Closed candle Open not recomputed")
+	require.Equal(t, 90.0, closedCandle.Low, "Closed candle Low not recomputed")
+	require.Equal(t, 120.0, closedCandle.Close, "Closed candle Close changed unexpectedly")
+
+	// Move a trade into the current candle.
+	moved := trades[1]
+	moved.Timestamp = time.Date(2023, 1, 1, 10, 1, 20, 0, time.UTC).UnixMilli()
+	require.True(t, builder.CorrectTrade(trades[1], moved), "Expected trade 2 to be corrected")
+
+	closedCandle = builder.closed[closedTime]
+	require.Equal(t, 90.0, closedCandle.High, "Closed candle High not recomputed")
+	require.Equal(t, 90.0, closedCandle.Volume, "Closed candle Volume not recomputed")
+	require.Equal(t, 120.0, builder.current.High, "Current candle High not recomputed")
+	require.Equal(t, 120.0, builder.current.Close, "Current candle Close not recomputed")
+	require.Equal(t, 230.0, builder.current.Volume, "Current candle Volume not recomputed")
+	require.Equal(t, int64(0), builder.current.Revision, "Current candle Revision changed unexpectedly")
+
+	require.False(t, builder.CorrectTrade(models.Trade{TradeID: "4", Timestamp: moved.Timestamp}, moved), "Expected unknown trade to fail")
+}
+
+func TestCandlesSince(t *testing.T) {
+	builder := NewBuilder(CandleBuilderParams{Interval: BuilderInterval1m})
+	require.Empty(t, builder.CandlesSince(0), "Expected no candles")
+
+	base := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	builder.ProcessTrades([]models.Trade{
+		{TradeID: "1", Timestamp: base.Add(10 * time.Second).UnixMilli(), Price: 100, Size: 1},
+		{TradeID: "2", Timestamp: base.Add(2 * time.Minute).UnixMilli(), Price: 110, Size: 1},
+		{TradeID: "3", Timestamp: base.Add(4 * time.Minute).UnixMilli(), Price: 120, Size: 1},
+	})
+
+	timestamps := func(candles models.CandleList) []int64 {
+		ts := make([]int64, 0, len(candles))
+		for _, c := range candles {
+			ts = append(ts, c.Timestamp)
+		}
+		return ts
+	}
+
+	all := []int64{
+		base.Add(time.Minute).UnixMilli(),
+		base.Add(3 * time.Minute).UnixMilli(),
+		base.Add(5 * time.Minute).UnixMilli(),
+	}
+	require.Equal(t, all, timestamps(builder.CandlesSince(0)), "Expected all candles")
+	require.Equal(t, all[1:], timestamps(builder.CandlesSince(all[0])), "Expected candles after the first")
+	require.Empty(t, builder.CandlesSince(all[2]), "Expected no candles after the current")
+}
@@ -0,0 +1,151 @@
+package logic
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+)
+
+// CandleSeries builds the candles of a symbol at several intervals.
+//
+// Only the builder of the finest interval consumes trades, the candles of
+// coarser intervals are rolled up from its candles whenever they change.
+// This keeps the work per trade independent of the number of intervals
+// and the intervals consistent with each other, as late trades are only
+// ever accepted or dropped by the finest interval.
+type CandleSeries struct {
+	// mtx serializes updates so the coarser
+	// builders are rolled up in order.
+	mtx sync.Mutex
+	// builders are sorted by interval, the first being the finest.
+	builders []*CandleBuilder
+}
+
+// NewCandleSeries creates a series building candles at every interval
+// given, which must all be multiples of the finest one. The Interval of
+// the params is ignored.
+func NewCandleSeries(p CandleBuilderParams, intervals []BuilderInterval) *CandleSeries {
+	intervals = slices.Clone(intervals)
+	slices.Sort(intervals)
+
+	series := &CandleSeries{builders: make([]*CandleBuilder, 0, len(intervals))}
+	for _, intvl := range intervals {
+		params := p
+		params.Interval = intvl
+
+		builder := NewBuilder(params)
+		if len(series.builders) > 0 {
+			builder.source = series.builders[0]
+		}
+		series.builders = append(series.builders, builder)
+	}
+	return series
+}
+
+// Builder returns the builder of the interval, or nil if the
+// series doesn't build candles at that interval.
+//
+// The builder must only be read from, updates go through the series.
+func (s *CandleSeries) Builder(intvl BuilderInterval) *CandleBuilder {
+	for _, builder := range s.builders {
+		if builder.p.Interval == intvl {
+			return builder
+		}
+	}
+	return nil
+}
+
+// ProcessTrades batch updates the series with the trades given.
+func (s *CandleSeries) ProcessTrades(trades []models.Trade) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	base := s.builders[0]
+	base.ProcessTrades(trades)
+
+	candleTimes := make([]int64, 0, len(trades))
+	for _, t := range trades {
+		candleTimes = append(candleTimes, base.candleTime(t))
+	}
+	s.rollUp(candleTimes)
+}
+
+// CancelTrade reverses the effect of a previously processed trade on
+// every interval, see CandleBuilder.CancelTrade.
+func (s *CandleSeries) CancelTrade(t models.Trade) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	base := s.builders[0]
+	if !base.CancelTrade(t) {
+		return false
+	}
+
+	s.rollUp([]int64{base.candleTime(t)})
+	return true
+}
+
+// CorrectTrade replaces a previously processed trade with its amended
+// version on every interval, see CandleBuilder.CorrectTrade.
+func (s *CandleSeries) CorrectTrade(original, amended models.Trade) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	base := s.builders[0]
+	if !base.CorrectTrade(original, amended) {
+		return false
+	}
+
+	s.rollUp([]int64{base.candleTime(original), base.candleTime(amended)})
+	return true
+}
+
+// Replace swaps in the candles of the rebuilt series closing after from
+// and up to to(ms), for the given interval only or every interval when
+// intvl is 0. Both bounds must be multiples of the replaced intervals.
+//
+// Readers see either the old or the rebuilt candles of every interval.
+func (s *CandleSeries) Replace(rebuilt *CandleSeries, intvl BuilderInterval, from, to int64) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	rebuilt.mtx.Lock()
+	defer rebuilt.mtx.Unlock()
+
+	type pair struct{ builder, rebuilt *CandleBuilder }
+	pairs := make([]pair, 0, len(s.builders))
+	for _, builder := range s.builders {
+		if intvl != 0 && builder.p.Interval != intvl {
+			continue
+		}
+		if other := rebuilt.Builder(builder.p.Interval); other != nil {
+			pairs = append(pairs, pair{builder, other})
+		}
+	}
+
+	// Lock every builder before replacing any candle.
+	for _, p := range pairs {
+		p.builder.mtx.Lock()
+		defer p.builder.mtx.Unlock()
+		p.rebuilt.mtx.RLock()
+		defer p.rebuilt.mtx.RUnlock()
+	}
+
+	for _, p := range pairs {
+		p.builder.replaceCandles(p.rebuilt, from, to)
+	}
+}
+
+// rollUp updates the coarser builders from the
+// candles of the finest one at the given times.
+func (s *CandleSeries) rollUp(candleTimes []int64) {
+	if len(candleTimes) == 0 {
+		return
+	}
+
+	slices.Sort(candleTimes)
+	candleTimes = slices.Compact(candleTimes)
+	for _, builder := range s.builders[1:] {
+		builder.rollUp(candleTimes)
+	}
+}
@@ -0,0 +1,139 @@
+package logic
+
+import (
+	"os"
+	"testing"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+	"github.com/mailru/easyjson"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestTrades(t *testing.T) map[string][]models.Trade {
+	payload, err := os.ReadFile("../testdata/trades.json")
+	require.NoError(t, err, "Failed to read trades")
+
+	var trades models.TradeList
+	require.NoError(t, easyjson.Unmarshal(payload, &trades), "Failed to parse trades")
+
+	bySymbol := make(map[string][]models.Trade)
+	for _, trade := range trades {
+		bySymbol[trade.Symbol] = append(bySymbol[trade.Symbol], trade)
+	}
+	return bySymbol
+}
+
+func requireCandlesEqual(t *testing.T, expected, actual models.CandleList, msg string) {
+	require.Lenf(t, actual, len(expected), "%s: candle count mismatch", msg)
+	for i := range expected {
+		// Volumes are summed in a different order.
+		require.InDeltaf(t, expected[i].Volume, actual[i].Volume, 1e-6, "%s: volume mismatch at %d", msg, i)
+		expected[i].Volume = actual[i].Volume
+		require.Equalf(t, expected[i], actual[i], "%s: candle mismatch at %d", msg, i)
+	}
+}
+
+// minuteTrade returns a trade of size 1 just after the start of the minute.
+func minuteTrade(id string, minute int64, price float64) models.Trade {
+	return models.Trade{TradeID: id, Timestamp: minute*60_000 + 1, Price: price, Size: 1}
+}
+
+func TestCandleSeries_MatchesIndependentBuilders(t *testing.T) {
+	for symbol, trades := range loadTestTrades(t) {
+		series := NewCandleSeries(CandleBuilderParams{}, BuilderIntervals)
+
+		// Ingest in a few batches like the server would.
+		for start := 0; start < len(trades); start += 7 {
+			series.ProcessTrades(trades[start:min(start+7, len(trades))])
+		}
+
+		for _, intvl := range BuilderIntervals {
+			builder := NewBuilder(CandleBuilderParams{Interval: intvl})
+			builder.ProcessTrades(trades)
+
+			requireCandlesEqual(t, builder.GetCandles(), series.Builder(intvl).GetCandles(), symbol+" "+intvl.String())
+		}
+	}
+}
+
+func TestCandleSeries_CancelAndCorrect(t *testing.T) {
+	trades := loadTestTrades(t)["BTC-PERP"]
+	series := NewCandleSeries(CandleBuilderParams{}, BuilderIntervals)
+	series.ProcessTrades(trades)
+
+	amended := trades[10]
+	amended.Price *= 2
+	amended.Timestamp = trades[40].Timestamp
+	require.True(t, series.CorrectTrade(trades[10], amended), "Expected trade to be corrected")
+	require.True(t, series.CancelTrade(trades[20]), "Expected trade to be cancelled")
+	require.False(t, series.CancelTrade(trades[20]), "Expected trade to be cancelled once")
+
+	// Every interval matches a series built from the amended trades, the
+	// amended trade being applied after the other trades of its candle.
+	expectedTrades := append([]models.Trade{}, trades[:10]...)
+	expectedTrades = append(expectedTrades, trades[11:20]...)
+	expectedTrades = append(expectedTrades, trades[21:]...)
+	expectedTrades = append(expectedTrades, amended)
+
+	expectedSeries := NewCandleSeries(CandleBuilderParams{}, BuilderIntervals)
+	expectedSeries.ProcessTrades(expectedTrades)
+
+	for _, intvl := range BuilderIntervals {
+		expected := expectedSeries.Builder(intvl).GetCandles()
+		actual := series.Builder(intvl).GetCandles()
+		// Revisions reflect the amendments rather than the trades.
+		for i := range expected {
+			expected[i].Revision = 0
+		}
+		for i := range actual {
+			actual[i].Revision = 0
+		}
+		requireCandlesEqual(t, expected, actual, intvl.String())
+	}
+}
+
+func TestCandleSeries_LateTradeRevision(t *testing.T) {
+	series := NewCandleSeries(CandleBuilderParams{}, []BuilderInterval{BuilderInterval5m, BuilderInterval1m})
+	series.ProcessTrades([]models.Trade{minuteTrade("1", 0, 10), minuteTrade("2", 6, 11)})
+	// Late for the 1m candles but not for the current 5m candle.
+	series.ProcessTrades([]models.Trade{minuteTrade("3", 5, 12)})
+	// Late for both.
+	series.ProcessTrades([]models.Trade{minuteTrade("4", 1, 9)})
+
+	candles := series.Builder(BuilderInterval5m).GetCandles()
+	require.Len(t, candles, 2, "Expected two 5m candles")
+	require.Equal(t, models.Candle{Timestamp: 300_000, Open: 10, High: 10, Low: 9, Close: 9, Volume: 19, Revision: 1}, candles[0], "Amended candle mismatch")
+	require.Equal(t, models.Candle{Timestamp: 600_000, Open: 12, High: 12, Low: 11, Close: 11, Volume: 23}, candles[1], "Current candle mismatch")
+
+	require.Equal(t, LateStats{Late: 2}, series.Builder(BuilderInterval5m).LateStats(), "Expected the late stats of the 1m interval")
+}
+
+func TestCandleSeries_Replace(t *testing.T) {
+	trades := []models.Trade{minuteTrade("1", 0, 10), minuteTrade("2", 3, 11), minuteTrade("3", 6, 12), minuteTrade("4", 11, 13)}
+
+	series := NewCandleSeries(CandleBuilderParams{}, []BuilderInterval{BuilderInterval1m, BuilderInterval5m})
+	series.ProcessTrades(trades)
+
+	// Rebuild the second 5m candle with a different price.
+	fixed := minuteTrade("3", 6, 15)
+	rebuilt := NewCandleSeries(CandleBuilderParams{}, []BuilderInterval{BuilderInterval1m, BuilderInterval5m})
+	rebuilt.ProcessTrades([]models.Trade{fixed})
+	series.Replace(rebuilt, 0, 300_000, 600_000)
+
+	candles := series.Builder(BuilderInterval5m).GetCandles()
+	require.Len(t, candles, 3, "Expected candles outside the range to be kept")
+	require.Equal(t, 11.0, candles[0].Close, "Candle before the range changed")
+	require.Equal(t, models.Candle{Timestamp: 600_000, Open: 15, High: 15, Low: 15, Close: 15, Volume: 15, Revision: 1}, candles[1], "Replaced candle mismatch")
+	require.Equal(t, 13.0, candles[2].Close, "Current candle changed")
+
+	// The replaced trades can still be cancelled.
+	require.True(t, series.CancelTrade(fixed), "Expected the rebuilt trade to be cancelled")
+	require.Len(t, series.Builder(BuilderInterval1m).GetCandles(), 3, "Expected the cancelled candle to be removed")
+
+	// Replacing a single interval leaves the others alone.
+	rebuilt = NewCandleSeries(CandleBuilderParams{}, []BuilderInterval{BuilderInterval1m, BuilderInterval5m})
+	rebuilt.ProcessTrades(trades)
+	series.Replace(rebuilt, BuilderInterval5m, 0, 900_000)
+	require.Len(t, series.Builder(BuilderInterval1m).GetCandles(), 3, "Expected the 1m candles to be kept")
+	require.Equal(t, 12.0, series.Builder(BuilderInterval5m).GetCandles()[1].Close, "Expected the 5m candles to be replaced")
+}
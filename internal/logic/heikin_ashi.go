@@ -0,0 +1,69 @@
+package logic
+
+import (
+	"sync"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+)
+
+// HeikinAshiTracker keeps the Heikin-Ashi view of the candles of a
+// CandleBuilder, transforming only the candles closed since the last
+// update and the live candle.
+type HeikinAshiTracker struct {
+	mtx sync.Mutex
+	// candles holds the transformed closed candles.
+	candles models.CandleList
+	cursor  seriesCursor
+}
+
+func NewHeikinAshiTracker() *HeikinAshiTracker {
+	return &HeikinAshiTracker{}
+}
+
+// Candles returns the Heikin-Ashi candles for the candles of the
+// builder in chronological order, including the current candle.
+//
+// The view is recomputed from the first candle if any closed
+// candle was amended since the last call, or the builder changed.
+func (t *HeikinAshiTracker) Candles(b *CandleBuilder) models.CandleList {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	closed, current, reset := t.cursor.next(b)
+	if reset {
+		t.candles = nil
+	}
+
+	for _, c := range closed {
+		t.candles = append(t.candles, heikinAshi(t.last(), c))
+	}
+
+	candles := make(models.CandleList, len(t.candles), len(t.candles)+1)
+	copy(candles, t.candles)
+	if current != nil {
+		candles = append(candles, heikinAshi(t.last(), *current))
+	}
+	return candles
+}
+
+func (t *HeikinAshiTracker) last() *models.Candle {
+	if len(t.candles) == 0 {
+		return nil
+	}
+	return &t.candles[len(t.candles)-1]
+}
+
+// heikinAshi transforms the candle given the previous Heikin-Ashi
+// candle, which is nil for the first candle of a series.
+func heikinAshi(prev *models.Candle, c models.Candle) models.Candle {
+	ha := c
+	ha.Close = (c.Open + c.High + c.Low + c.Close) / 4
+	if prev == nil {
+		ha.Open = (c.Open + c.Close) / 2
+	} else {
+		ha.Open = (prev.Open + prev.Close) / 2
+	}
+	ha.High = max(c.High, ha.Open, ha.Close)
+	ha.Low = min(c.Low, ha.Open, ha.Close)
+	return ha
+}
@@ -0,0 +1,50 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeikinAshi(t *testing.T) {
+	first := heikinAshi(nil, models.Candle{Open: 10, High: 14, Low: 8, Close: 12, Volume: 5, Timestamp: 60_000})
+	require.Equal(t, models.Candle{Open: 11, High: 14, Low: 8, Close: 11, Volume: 5, Timestamp: 60_000}, first, "First candle mismatch")
+
+	second := heikinAshi(&first, models.Candle{Open: 12, High: 13, Low: 11.5, Close: 13, Timestamp: 120_000})
+	// Open is the midpoint of the previous candle, close the OHLC average.
+	require.Equal(t, 11.0, second.Open, "Open mismatch")
+	require.Equal(t, 12.375, second.Close, "Close mismatch")
+	require.Equal(t, 13.0, second.High, "High mismatch")
+	require.Equal(t, 11.0, second.Low, "Low should include the open")
+}
+
+func TestHeikinAshiTracker_Candles(t *testing.T) {
+	builder := NewBuilder(CandleBuilderParams{Interval: BuilderInterval1m})
+	tracker := NewHeikinAshiTracker()
+
+	// transformAll transforms every candle of the builder from scratch.
+	transformAll := func() models.CandleList {
+		var expected models.CandleList
+		for _, c := range builder.GetCandles() {
+			var prev *models.Candle
+			if len(expected) > 0 {
+				prev = &expected[len(expected)-1]
+			}
+			expected = append(expected, heikinAshi(prev, c))
+		}
+		return expected
+	}
+
+	require.Empty(t, tracker.Candles(builder), "Expected no candles")
+
+	builder.ProcessTrades([]models.Trade{minuteTrade("1", 0, 10), minuteTrade("2", 0, 12)})
+	require.Equal(t, transformAll(), tracker.Candles(builder), "Live candle mismatch")
+
+	builder.ProcessTrades([]models.Trade{minuteTrade("3", 1, 11), minuteTrade("4", 2, 13)})
+	require.Equal(t, transformAll(), tracker.Candles(builder), "Candles mismatch after candles closed")
+
+	// A late trade amends the first candle, which changes every candle after it.
+	builder.ProcessTrades([]models.Trade{minuteTrade("5", 0, 20)})
+	require.Equal(t, transformAll(), tracker.Candles(builder), "Candles mismatch after a late trade")
+}
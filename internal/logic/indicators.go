@@ -0,0 +1,413 @@
+package logic
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+)
+
+// Indicator is a technical indicator computed incrementally
+// over a candle series, one candle at a time.
+type Indicator interface {
+	// Fields names the values returned by the indicator.
+	Fields() []string
+	// Update commits a closed candle and returns the values of the
+	// indicator for it, or nil while the indicator is warming up.
+	Update(c models.Candle) []float64
+	// Peek returns the values the indicator would have for the live
+	// candle without committing it, as the live candle still changes.
+	Peek(c models.Candle) []float64
+}
+
+type indicatorDef struct {
+	defaults []float64
+	create   func(params []float64) Indicator
+}
+
+var indicatorDefs = map[string]indicatorDef{
+	"sma": {
+		defaults: []float64{20},
+		create:   func(p []float64) Indicator { return newSMA(int(p[0])) },
+	},
+	"ema": {
+		defaults: []float64{20},
+		create:   func(p []float64) Indicator { return &emaIndicator{ema: newEMA(int(p[0]))} },
+	},
+	"rsi": {
+		defaults: []float64{14},
+		create:   func(p []float64) Indicator { return &rsiIndicator{period: int(p[0])} },
+	},
+	"macd": {
+		defaults: []float64{12, 26, 9},
+		create: func(p []float64) Indicator {
+			return &macdIndicator{fast: newEMA(int(p[0])), slow: newEMA(int(p[1])), signal: newEMA(int(p[2]))}
+		},
+	},
+	"bollinger": {
+		defaults: []float64{20, 2},
+		create:   func(p []float64) Indicator { return &bollingerIndicator{window: newWindow(int(p[0])), k: p[1]} },
+	},
+	"atr": {
+		defaults: []float64{14},
+		create:   func(p []float64) Indicator { return &atrIndicator{period: int(p[0])} },
+	},
+}
+
+// MaxIndicatorPeriod is the longest period of an indicator, in candles.
+const MaxIndicatorPeriod = 1000
+
+// NewIndicator creates the named indicator, one of sma, ema, rsi, macd,
+// bollinger or atr. Missing params are filled in with the defaults of the
+// indicator, so the returned params are the ones in use. Periods must be
+// whole numbers up to MaxIndicatorPeriod.
+func NewIndicator(name string, params []float64) (Indicator, []float64, error) {
+	def, ok := indicatorDefs[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown indicator %q", name)
+	}
+	if len(params) > len(def.defaults) {
+		return nil, nil, fmt.Errorf("%s takes at most %d params", name, len(def.defaults))
+	}
+
+	full := append([]float64{}, def.defaults...)
+	copy(full, params)
+	for i, p := range full {
+		// Every param is a period except the Bollinger multiplier.
+		period := !(name == "bollinger" && i == 1)
+		if p <= 0 || math.IsNaN(p) || math.IsInf(p, 0) || (period && p != math.Trunc(p)) {
+			return nil, nil, fmt.Errorf("invalid %s param %v", name, p)
+		}
+		if period && p > MaxIndicatorPeriod {
+			return nil, nil, fmt.Errorf("%s periods cannot exceed %d", name, MaxIndicatorPeriod)
+		}
+	}
+
+	return def.create(full), full, nil
+}
+
+// window is a fixed size window of the latest values.
+type window struct {
+	values []float64
+	next   int
+	count  int
+}
+
+func newWindow(size int) *window {
+	return &window{values: make([]float64, size)}
+}
+
+func (w *window) full() bool {
+	return w.count == len(w.values)
+}
+
+func (w *window) push(v float64) {
+	w.values[w.next] = v
+	w.next = (w.next + 1) % len(w.values)
+	w.count = min(w.count+1, len(w.values))
+}
+
+// with returns the values of the window as if v was pushed,
+// or nil if the window wouldn't be full.
+func (w *window) with(v float64) []float64 {
+	if w.count+1 < len(w.values) {
+		return nil
+	}
+
+	values := make([]float64, 0, len(w.values))
+	// Skip the oldest value when it would be pushed out.
+	skip := 0
+	if w.full() {
+		skip = 1
+	}
+	for i := skip; i < w.count; i++ {
+		values = append(values, w.values[(w.next-w.count+i+len(w.values))%len(w.values)])
+	}
+	return append(values, v)
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+type smaIndicator struct {
+	window *window
+}
+
+func newSMA(period int) *smaIndicator {
+	return &smaIndicator{window: newWindow(period)}
+}
+
+func (s *smaIndicator) Fields() []string { return []string{"sma"} }
+
+func (s *smaIndicator) Update(c models.Candle) []float64 {
+	values := s.Peek(c)
+	s.window.push(c.Close)
+	return values
+}
+
+func (s *smaIndicator) Peek(c models.Candle) []float64 {
+	values := s.window.with(c.Close)
+	if values == nil {
+		return nil
+	}
+	return []float64{mean(values)}
+}
+
+// ema is an exponential moving average seeded
+// with the simple average of its first period values.
+type ema struct {
+	period int
+	count  int
+	value  float64
+}
+
+func newEMA(period int) ema {
+	return ema{period: period}
+}
+
+func (e *ema) ready() bool {
+	return e.count >= e.period
+}
+
+func (e *ema) update(v float64) {
+	e.count++
+	if e.count <= e.period {
+		// Accumulate the seed average.
+		e.value += (v - e.value) / float64(e.count)
+		return
+	}
+	alpha := 2 / float64(e.period+1)
+	e.value += alpha * (v - e.value)
+}
+
+type emaIndicator struct {
+	ema ema
+}
+
+func (e *emaIndicator) Fields() []string { return []string{"ema"} }
+
+func (e *emaIndicator) Update(c models.Candle) []float64 {
+	e.ema.update(c.Close)
+	if !e.ema.ready() {
+		return nil
+	}
+	return []float64{e.ema.value}
+}
+
+func (e *emaIndicator) Peek(c models.Candle) []float64 {
+	peek := *e
+	return peek.Update(c)
+}
+
+// rsiIndicator is the relative strength index using Wilder's smoothing.
+type rsiIndicator struct {
+	period    int
+	count     int
+	prevClose float64
+	avgGain   float64
+	avgLoss   float64
+}
+
+func (r *rsiIndicator) Fields() []string { return []string{"rsi"} }
+
+func (r *rsiIndicator) Update(c models.Candle) []float64 {
+	r.count++
+	if r.count == 1 {
+		r.prevClose = c.Close
+		return nil
+	}
+
+	change := c.Close - r.prevClose
+	r.prevClose = c.Close
+	gain, loss := max(change, 0), max(-change, 0)
+
+	changes := r.count - 1
+	if changes <= r.period {
+		// Seed the averages with the simple average of the first changes.
+		r.avgGain += (gain - r.avgGain) / float64(changes)
+		r.avgLoss += (loss - r.avgLoss) / float64(changes)
+		if changes < r.period {
+			return nil
+		}
+	} else {
+		r.avgGain = (r.avgGain*float64(r.period-1) + gain) / float64(r.period)
+		r.avgLoss = (r.avgLoss*float64(r.period-1) + loss) / float64(r.period)
+	}
+
+	if r.avgLoss == 0 {
+		return []float64{100}
+	}
+	return []float64{100 - 100/(1+r.avgGain/r.avgLoss)}
+}
+
+func (r *rsiIndicator) Peek(c models.Candle) []float64 {
+	peek := *r
+	return peek.Update(c)
+}
+
+// macdIndicator is the moving average convergence divergence,
+// the signal line is an EMA of the MACD line.
+type macdIndicator struct {
+	fast   ema
+	slow   ema
+	signal ema
+}
+
+func (m *macdIndicator) Fields() []string { return []string{"macd", "signal", "histogram"} }
+
+func (m *macdIndicator) Update(c models.Candle) []float64 {
+	m.fast.update(c.Close)
+	m.slow.update(c.Close)
+	if !m.fast.ready() || !m.slow.ready() {
+		return nil
+	}
+
+	macd := m.fast.value - m.slow.value
+	m.signal.update(macd)
+	if !m.signal.ready() {
+		return nil
+	}
+	return []float64{macd, m.signal.value, macd - m.signal.value}
+}
+
+func (m *macdIndicator) Peek(c models.Candle) []float64 {
+	peek := *m
+	return peek.Update(c)
+}
+
+// bollingerIndicator are the Bollinger Bands, k population
+// standard deviations around the simple moving average.
+type bollingerIndicator struct {
+	window *window
+	k      float64
+}
+
+func (b *bollingerIndicator) Fields() []string { return []string{"middle", "upper", "lower"} }
+
+func (b *bollingerIndicator) Update(c models.Candle) []float64 {
+	values := b.Peek(c)
+	b.window.push(c.Close)
+	return values
+}
+
+func (b *bollingerIndicator) Peek(c models.Candle) []float64 {
+	values := b.window.with(c.Close)
+	if values == nil {
+		return nil
+	}
+
+	middle := mean(values)
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - middle) * (v - middle)
+	}
+	dev := b.k * math.Sqrt(variance/float64(len(values)))
+
+	return []float64{middle, middle + dev, middle - dev}
+}
+
+// atrIndicator is the average true range using Wilder's smoothing.
+type atrIndicator struct {
+	period    int
+	count     int
+	prevClose float64
+	value     float64
+}
+
+func (a *atrIndicator) Fields() []string { return []string{"atr"} }
+
+func (a *atrIndicator) Update(c models.Candle) []float64 {
+	tr := c.High - c.Low
+	if a.count > 0 {
+		tr = max(tr, math.Abs(c.High-a.prevClose), math.Abs(c.Low-a.prevClose))
+	}
+	a.prevClose = c.Close
+	a.count++
+
+	if a.count <= a.period {
+		// Seed with the simple average of the first true ranges.
+		a.value += (tr - a.value) / float64(a.count)
+		if a.count < a.period {
+			return nil
+		}
+	} else {
+		a.value = (a.value*float64(a.period-1) + tr) / float64(a.period)
+	}
+	return []float64{a.value}
+}
+
+func (a *atrIndicator) Peek(c models.Candle) []float64 {
+	peek := *a
+	return peek.Update(c)
+}
+
+// IndicatorTracker keeps the values of an indicator over the candles
+// of a CandleBuilder, feeding the indicator only the candles closed
+// since the last update.
+type IndicatorTracker struct {
+	mtx       sync.Mutex
+	name      string
+	params    []float64
+	indicator Indicator
+	// points holds the values for the closed candles.
+	points []models.IndicatorPoint
+	cursor seriesCursor
+}
+
+// NewIndicatorTracker creates a tracker for the indicator
+// created by NewIndicator with the same arguments.
+func NewIndicatorTracker(name string, params []float64) (*IndicatorTracker, error) {
+	indicator, params, err := NewIndicator(name, params)
+	if err != nil {
+		return nil, err
+	}
+	return &IndicatorTracker{name: name, params: params, indicator: indicator}, nil
+}
+
+// Params returns the params of the indicator including the defaults.
+func (t *IndicatorTracker) Params() []float64 {
+	return t.params
+}
+
+// Fields names the values of each point.
+func (t *IndicatorTracker) Fields() []string {
+	return t.indicator.Fields()
+}
+
+// Points returns the indicator values for the candles of the builder in
+// chronological order, ending with a live point for the current candle.
+// Candles within the warm up of the indicator have no point.
+//
+// The indicator is recomputed from the first candle if any closed
+// candle was amended since the last call, or the builder changed.
+func (t *IndicatorTracker) Points(b *CandleBuilder) []models.IndicatorPoint {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	closed, current, reset := t.cursor.next(b)
+	if reset {
+		t.indicator, _, _ = NewIndicator(t.name, t.params)
+		t.points = nil
+	}
+
+	for _, c := range closed {
+		if values := t.indicator.Update(c); values != nil {
+			t.points = append(t.points, models.IndicatorPoint{Timestamp: c.Timestamp, Values: values})
+		}
+	}
+
+	points := make([]models.IndicatorPoint, len(t.points), len(t.points)+1)
+	copy(points, t.points)
+	if current != nil {
+		if values := t.indicator.Peek(*current); values != nil {
+			points = append(points, models.IndicatorPoint{Timestamp: current.Timestamp, Values: values, Live: true})
+		}
+	}
+	return points
+}
@@ -0,0 +1,181 @@
+package logic
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func closeCandles(closes ...float64) []models.Candle {
+	candles := make([]models.Candle, 0, len(closes))
+	for i, c := range closes {
+		candles = append(candles, models.Candle{
+			Timestamp: int64(i+1) * 60_000,
+			Open:      c,
+			High:      c + 1,
+			Low:       c - 1,
+			Close:     c,
+		})
+	}
+	return candles
+}
+
+func updateAll(ind Indicator, candles []models.Candle) [][]float64 {
+	values := make([][]float64, 0, len(candles))
+	for _, c := range candles {
+		values = append(values, ind.Update(c))
+	}
+	return values
+}
+
+func TestNewIndicator(t *testing.T) {
+	_, params, err := NewIndicator("macd", []float64{6})
+	require.NoError(t, err, "Failed to create macd")
+	require.Equal(t, []float64{6, 26, 9}, params, "Expected defaults for missing params")
+
+	_, params, err = NewIndicator("bollinger", []float64{10, 2.5})
+	require.NoError(t, err, "Failed to create bollinger")
+	require.Equal(t, []float64{10, 2.5}, params, "Params mismatch")
+
+	for _, tc := range []struct {
+		name   string
+		params []float64
+	}{
+		{"vwap", nil},
+		{"sma", []float64{0}},
+		{"sma", []float64{2.5}},
+		{"sma", []float64{5, 5}},
+		{"sma", []float64{MaxIndicatorPeriod + 1}},
+		{"macd", []float64{12, 1e19}},
+		{"bollinger", []float64{20, math.NaN()}},
+		{"bollinger", []float64{20, math.Inf(1)}},
+	} {
+		_, _, err := NewIndicator(tc.name, tc.params)
+		require.Errorf(t, err, "Expected %s%v to be invalid", tc.name, tc.params)
+	}
+}
+
+func TestSMA(t *testing.T) {
+	ind, _, _ := NewIndicator("sma", []float64{3})
+	values := updateAll(ind, closeCandles(1, 2, 3, 4, 5))
+	require.Equal(t, [][]float64{nil, nil, {2}, {3}, {4}}, values, "SMA mismatch")
+}
+
+func TestEMA(t *testing.T) {
+	ind, _, _ := NewIndicator("ema", []float64{3})
+	values := updateAll(ind, closeCandles(1, 2, 3, 4, 5))
+	// Seeded with the SMA, then alpha = 0.5.
+	require.Equal(t, [][]float64{nil, nil, {2}, {3}, {4}}, values, "EMA mismatch")
+}
+
+func TestRSI(t *testing.T) {
+	ind, _, _ := NewIndicator("rsi", []float64{2})
+	values := updateAll(ind, closeCandles(10, 11, 12, 11))
+	require.Nil(t, values[1], "Expected RSI to warm up")
+	require.Equal(t, []float64{100}, values[2], "Expected RSI of 100 without losses")
+	// avg gain (1+0)/2 = 0.5, avg loss (0+1)/2 = 0.5.
+	require.InDelta(t, 50, values[3][0], 1e-9, "RSI mismatch")
+}
+
+func TestMACD(t *testing.T) {
+	ind, _, _ := NewIndicator("macd", []float64{2, 3, 2})
+	values := updateAll(ind, closeCandles(1, 2, 3, 4, 5))
+	require.Nil(t, values[2], "Expected MACD to wait for the signal line")
+	// Both EMAs lag a linear series by a constant amount.
+	require.InDeltaSlice(t, []float64{0.5, 0.5, 0}, values[4], 1e-9, "MACD mismatch")
+}
+
+func TestBollinger(t *testing.T) {
+	ind, _, _ := NewIndicator("bollinger", []float64{2, 2})
+	values := updateAll(ind, closeCandles(1, 3))
+	// Mean 2, population deviation 1.
+	require.Equal(t, []float64{2, 4, 0}, values[1], "Bollinger mismatch")
+}
+
+func TestATR(t *testing.T) {
+	ind, _, _ := NewIndicator("atr", []float64{2})
+	candles := closeCandles(10, 10, 14)
+	values := updateAll(ind, candles)
+	require.Nil(t, values[0], "Expected ATR to warm up")
+	require.Equal(t, []float64{2}, values[1], "ATR mismatch")
+	// True range of the gap up is 15-10 = 5.
+	require.Equal(t, []float64{3.5}, values[2], "ATR mismatch")
+}
+
+func TestIndicator_PeekDoesNotCommit(t *testing.T) {
+	candles := closeCandles(1, 2, 3, 4, 5, 6)
+	for name := range indicatorDefs {
+		ind, _, _ := NewIndicator(name, []float64{2})
+		ref, _, _ := NewIndicator(name, []float64{2})
+
+		for _, c := range candles {
+			live := c
+			live.Close += 100
+			ind.Peek(live)
+
+			require.Equalf(t, ref.Peek(c), ind.Peek(c), "%s peek mismatch", name)
+			require.Equalf(t, ref.Update(c), ind.Update(c), "%s update mismatch", name)
+		}
+	}
+}
+
+func TestIndicatorTracker_Points(t *testing.T) {
+	builder := NewBuilder(CandleBuilderParams{Interval: BuilderInterval1m})
+	tracker, err := NewIndicatorTracker("sma", []float64{2})
+	require.NoError(t, err, "Failed to create tracker")
+
+	require.Empty(t, tracker.Points(builder), "Expected no points without candles")
+
+	builder.ProcessTrades([]models.Trade{minuteTrade("1", 0, 1), minuteTrade("2", 1, 3)})
+	require.Equal(t, []models.IndicatorPoint{
+		{Timestamp: 120_000, Values: []float64{2}, Live: true},
+	}, tracker.Points(builder), "Points mismatch")
+
+	builder.ProcessTrades([]models.Trade{minuteTrade("3", 2, 5)})
+	require.Equal(t, []models.IndicatorPoint{
+		{Timestamp: 120_000, Values: []float64{2}},
+		{Timestamp: 180_000, Values: []float64{4}, Live: true},
+	}, tracker.Points(builder), "Points mismatch after a candle closed")
+
+	// Amending a closed candle recomputes the points.
+	builder.ProcessTrades([]models.Trade{minuteTrade("4", 0, 3)})
+	require.Equal(t, []models.IndicatorPoint{
+		{Timestamp: 120_000, Values: []float64{3}},
+		{Timestamp: 180_000, Values: []float64{4}, Live: true},
+	}, tracker.Points(builder), "Points mismatch after a late trade")
+}
+
+func TestIndicatorTracker_MatchesFullRecompute(t *testing.T) {
+	builder := NewBuilder(CandleBuilderParams{Interval: BuilderInterval1m})
+	tracker, _ := NewIndicatorTracker("macd", []float64{3, 5, 2})
+
+	for i := int64(0); i < 40; i++ {
+		builder.ProcessTrades([]models.Trade{{
+			TradeID:   fmt.Sprint(i),
+			Timestamp: i*30_000 + 1,
+			Price:     100 + float64(i%7),
+			Size:      1,
+		}})
+		points := tracker.Points(builder)
+
+		full, _, _ := NewIndicator("macd", []float64{3, 5, 2})
+		candles := builder.GetCandles()
+		var expected []models.IndicatorPoint
+		for _, c := range candles[:len(candles)-1] {
+			if values := full.Update(c); values != nil {
+				expected = append(expected, models.IndicatorPoint{Timestamp: c.Timestamp, Values: values})
+			}
+		}
+		if values := full.Peek(candles[len(candles)-1]); values != nil {
+			expected = append(expected, models.IndicatorPoint{Timestamp: candles[len(candles)-1].Timestamp, Values: values, Live: true})
+		}
+
+		require.Equalf(t, len(expected), len(points), "Point count mismatch after trade %d", i)
+		if len(expected) > 0 {
+			require.Equalf(t, expected, points, "Points mismatch after trade %d", i)
+		}
+	}
+}
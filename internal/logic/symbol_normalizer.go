@@ -0,0 +1,122 @@
+package logic
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+	"github.com/mailru/easyjson"
+)
+
+type normalizationRule struct {
+	match   *regexp.Regexp
+	replace string
+}
+
+// SymbolNormalizer maps the symbols sent by different venues,
+// e.g. btcusd, BTC/USD and XBTUSD, to a single canonical symbol.
+//
+// Symbols are trimmed and upper cased, then resolved as an alias,
+// otherwise rewritten by the first matching rule and the result
+// trimmed, upper cased and resolved as an alias again. Canonical
+// symbols are always trimmed and upper cased.
+type SymbolNormalizer struct {
+	// Keyed by upper cased alias, the canonical symbols are upper cased.
+	aliases map[string]string
+	rules   []normalizationRule
+}
+
+// NewSymbolNormalizer creates a normalizer from its configuration,
+// returning an error if any of the rules are invalid.
+func NewSymbolNormalizer(cfg models.SymbolNormalization) (*SymbolNormalizer, error) {
+	n := &SymbolNormalizer{
+		aliases: make(map[string]string, len(cfg.Aliases)),
+		rules:   make([]normalizationRule, 0, len(cfg.Rules)),
+	}
+
+	for alias, canonical := range cfg.Aliases {
+		canonical = normalizeCase(canonical)
+		if canonical == "" {
+			return nil, fmt.Errorf("alias %q has no canonical symbol", alias)
+		}
+		key := normalizeCase(alias)
+		if other, exists := n.aliases[key]; exists && other != canonical {
+			return nil, fmt.Errorf("alias %q is mapped to both %s and %s", key, other, canonical)
+		}
+		n.aliases[key] = canonical
+	}
+
+	for _, rule := range cfg.Rules {
+		match, err := regexp.Compile(rule.Match)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %q: %w", rule.Match, err)
+		}
+		n.rules = append(n.rules, normalizationRule{match: match, replace: rule.Replace})
+	}
+
+	return n, nil
+}
+
+// LoadSymbolNormalizer creates a normalizer from a JSON file
+// containing its configuration.
+func LoadSymbolNormalizer(path string) (*SymbolNormalizer, error) {
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg models.SymbolNormalization
+	if err := easyjson.Unmarshal(payload, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return NewSymbolNormalizer(cfg)
+}
+
+// Normalize returns the canonical symbol for the given symbol.
+func (n *SymbolNormalizer) Normalize(symbol string) string {
+	symbol = normalizeCase(symbol)
+	if canonical, ok := n.aliases[symbol]; ok {
+		return canonical
+	}
+
+	for _, rule := range n.rules {
+		if rule.match.MatchString(symbol) {
+			symbol = normalizeCase(rule.match.ReplaceAllString(symbol, rule.replace))
+			break
+		}
+	}
+
+	if canonical, ok := n.aliases[symbol]; ok {
+		return canonical
+	}
+	return symbol
+}
+
+// NormalizeAll returns the canonical symbol of each of the symbols, keyed
+// by symbol, for configuration keyed by symbol. It returns an error if
+// different symbols are the same once normalized, like btc-usd and BTC-USD.
+func (n *SymbolNormalizer) NormalizeAll(symbols []string) (map[string]string, error) {
+	symbols = slices.Clone(symbols)
+	slices.Sort(symbols)
+
+	canonical := make(map[string]string, len(symbols))
+	// Keyed by canonical symbol.
+	seen := make(map[string]string, len(symbols))
+	for _, symbol := range symbols {
+		c := n.Normalize(symbol)
+		if other, exists := seen[c]; exists && other != symbol {
+			return nil, fmt.Errorf("%q and %q are both %s once normalized", other, symbol, c)
+		}
+		seen[c] = symbol
+		canonical[symbol] = c
+	}
+	return canonical, nil
+}
+
+func normalizeCase(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
@@ -0,0 +1,62 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSymbolNormalizer_Normalize(t *testing.T) {
+	normalizer, err := LoadSymbolNormalizer("../testdata/symbol_aliases.json")
+	require.NoError(t, err, "Failed to load normalizer")
+
+	testCases := map[string]string{
+		"BTC_USD":   "BTC_USD",
+		"btcusd":    "BTC_USD",
+		"XBTUSD":    "BTC_USD",
+		"BTC/USD":   "BTC_USD",
+		" eth:usd ": "ETH_USD",
+		"XRP-PERP":  "XRP-PERP",
+		"xrp-perp":  "XRP-PERP",
+	}
+	for input, expected := range testCases {
+		require.Equalf(t, expected, normalizer.Normalize(input), "Normalize(%q) mismatch", input)
+	}
+}
+
+func TestSymbolNormalizer_RuleResultIsAliased(t *testing.T) {
+	normalizer, err := NewSymbolNormalizer(models.SymbolNormalization{
+		Aliases: map[string]string{"XBT_USD": "BTC_USD"},
+		Rules:   []models.NormalizationRule{{Match: "^([A-Z]+)/([A-Z]+)$", Replace: "${1}_${2}"}},
+	})
+	require.NoError(t, err, "Failed to create normalizer")
+	require.Equal(t, "BTC_USD", normalizer.Normalize("xbt/usd"), "Expected rule result to be aliased")
+}
+
+func TestSymbolNormalizer_CanonicalIsUpperCased(t *testing.T) {
+	normalizer, err := NewSymbolNormalizer(models.SymbolNormalization{
+		Aliases: map[string]string{"XBTUSD": " btc_usd"},
+		Rules:   []models.NormalizationRule{{Match: "^([A-Z]+)-USD$", Replace: "${1}_usd "}},
+	})
+	require.NoError(t, err, "Failed to create normalizer")
+	require.Equal(t, "BTC_USD", normalizer.Normalize("xbtusd"), "Expected alias targets to be upper cased")
+	require.Equal(t, "ETH_USD", normalizer.Normalize("eth-usd"), "Expected rule results to be upper cased")
+}
+
+func TestNewSymbolNormalizer_Invalid(t *testing.T) {
+	_, err := NewSymbolNormalizer(models.SymbolNormalization{
+		Rules: []models.NormalizationRule{{Match: "(", Replace: ""}},
+	})
+	require.Error(t, err, "Expected invalid rule to fail")
+
+	_, err = NewSymbolNormalizer(models.SymbolNormalization{
+		Aliases: map[string]string{"XBTUSD": ""},
+	})
+	require.Error(t, err, "Expected empty canonical symbol to fail")
+
+	_, err = NewSymbolNormalizer(models.SymbolNormalization{
+		Aliases: map[string]string{"XBTUSD": "BTC_USD", "xbtusd": "ETH_USD"},
+	})
+	require.Error(t, err, "Expected aliases mapped to different symbols once normalized to fail")
+}
@@ -0,0 +1,236 @@
+package logic
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+	"github.com/mailru/easyjson"
+)
+
+var (
+	ErrUnknownSymbol    = errors.New("unknown symbol")
+	ErrSymbolNotTrading = errors.New("symbol is not trading")
+	ErrInvalidTick      = errors.New("price is not a multiple of the tick size")
+	ErrInvalidLot       = errors.New("size is not a multiple of the lot size")
+)
+
+var validSymbolStatuses = map[string]struct{}{
+	models.SymbolStatusTrading:  {},
+	models.SymbolStatusHalted:   {},
+	models.SymbolStatusDelisted: {},
+}
+
+// SymbolRegistry holds the metadata of every symbol that
+// trades are accepted for.
+//
+// Whether trades are validated is decided when the registry is created,
+// so removing every symbol doesn't start accepting any trade.
+type SymbolRegistry struct {
+	mtx sync.RWMutex
+	// Keyed by Symbol
+	symbols map[string]models.Symbol
+	// validate is false for registries accepting trades for any symbol.
+	validate bool
+}
+
+// NewSymbolRegistry creates a registry of the given symbols,
+// returning an error if any of them are invalid. Trades are
+// rejected unless their symbol is registered.
+func NewSymbolRegistry(symbols []models.Symbol) (*SymbolRegistry, error) {
+	r := &SymbolRegistry{
+		symbols:  make(map[string]models.Symbol, len(symbols)),
+		validate: true,
+	}
+
+	for _, s := range symbols {
+		if err := ValidateSymbol(s); err != nil {
+			return nil, err
+		}
+		if _, exists := r.symbols[s.Symbol]; exists {
+			return nil, fmt.Errorf("symbol %s is defined more than once", s.Symbol)
+		}
+		r.symbols[s.Symbol] = s
+	}
+
+	return r, nil
+}
+
+// NewOpenSymbolRegistry creates an empty registry that accepts trades
+// for any symbol, for when no registry is configured. Symbols added to
+// it only provide their metadata.
+func NewOpenSymbolRegistry() *SymbolRegistry {
+	return &SymbolRegistry{symbols: make(map[string]models.Symbol)}
+}
+
+// LoadSymbolRegistry creates a registry from a JSON file containing
+// an array of symbols, which are normalized by the normalizer.
+func LoadSymbolRegistry(path string, normalizer *SymbolNormalizer) (*SymbolRegistry, error) {
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var symbols models.SymbolList
+	if err := easyjson.Unmarshal(payload, &symbols); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(symbols))
+	for _, s := range symbols {
+		names = append(names, s.Symbol)
+	}
+	canonical, err := normalizer.NormalizeAll(names)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range symbols {
+		symbols[i].Symbol = canonical[symbols[i].Symbol]
+	}
+
+	return NewSymbolRegistry(symbols)
+}
+
+// ValidateSymbol checks that the symbol metadata is usable.
+func ValidateSymbol(s models.Symbol) error {
+	if s.Symbol == "" {
+		return errors.New("symbol is required")
+	}
+	if _, ok := validSymbolStatuses[s.Status]; !ok {
+		return fmt.Errorf("symbol %s has invalid status %q", s.Symbol, s.Status)
+	}
+	if s.TickSize < 0 || s.LotSize < 0 {
+		return fmt.Errorf("symbol %s tick and lot size cannot be negative", s.Symbol)
+	}
+	if s.PricePrecision < 0 {
+		return fmt.Errorf("symbol %s price precision cannot be negative", s.Symbol)
+	}
+	return nil
+}
+
+// Get returns the metadata of the symbol.
+func (r *SymbolRegistry) Get(symbol string) (models.Symbol, bool) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	s, ok := r.symbols[symbol]
+	return s, ok
+}
+
+// List returns the metadata of every symbol sorted by symbol.
+func (r *SymbolRegistry) List() []models.Symbol {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	symbols := make([]models.Symbol, 0, len(r.symbols))
+	for _, s := range r.symbols {
+		symbols = append(symbols, s)
+	}
+
+	sort.Slice(symbols, func(i, j int) bool {
+		return symbols[i].Symbol < symbols[j].Symbol
+	})
+	return symbols
+}
+
+// Upsert adds or replaces the metadata of a symbol.
+func (r *SymbolRegistry) Upsert(s models.Symbol) error {
+	if err := ValidateSymbol(s); err != nil {
+		return err
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.symbols[s.Symbol] = s
+	return nil
+}
+
+// Delete removes the symbol, returning false if it wasn't registered.
+func (r *SymbolRegistry) Delete(symbol string) bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	_, exists := r.symbols[symbol]
+	delete(r.symbols, symbol)
+	return exists
+}
+
+// Replace swaps the symbols of the registry for the symbols of o, and
+// validates trades only if o does, returning the metadata of the symbols before and after.
+func (r *SymbolRegistry) Replace(o *SymbolRegistry) (before, after []models.Symbol) {
+	before = r.List()
+	after = o.List()
+
+	symbols := make(map[string]models.Symbol, len(after))
+	for _, s := range after {
+		symbols[s.Symbol] = s
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.symbols = symbols
+	r.validate = o.validate
+	return before, after
+}
+
+// ValidateTrade checks the trade against the metadata of its symbol.
+func (r *SymbolRegistry) ValidateTrade(t models.Trade) error {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	if !r.validate {
+		return nil
+	}
+
+	s, ok := r.symbols[t.Symbol]
+	if !ok {
+		return ErrUnknownSymbol
+	}
+	if s.Status != models.SymbolStatusTrading {
+		return ErrSymbolNotTrading
+	}
+	if !isMultiple(t.Price, s.TickSize) {
+		return ErrInvalidTick
+	}
+	if !isMultiple(t.Size, s.LotSize) {
+		return ErrInvalidLot
+	}
+	return nil
+}
+
+// RoundCandles rounds the prices of the candles to the price precision
+// of the symbol. Candles of unregistered symbols are left as is.
+func (r *SymbolRegistry) RoundCandles(symbol string, candles models.CandleList) {
+	s, ok := r.Get(symbol)
+	if !ok {
+		return
+	}
+
+	for i := range candles {
+		candles[i].Open = roundTo(candles[i].Open, s.PricePrecision)
+		candles[i].High = roundTo(candles[i].High, s.PricePrecision)
+		candles[i].Low = roundTo(candles[i].Low, s.PricePrecision)
+		candles[i].Close = roundTo(candles[i].Close, s.PricePrecision)
+	}
+}
+
+// isMultiple reports whether v is a multiple of step, allowing for the
+// error of float64. A step of zero means any value is allowed.
+func isMultiple(v, step float64) bool {
+	if step == 0 {
+		return true
+	}
+	ratio := v / step
+	return math.Abs(ratio-math.Round(ratio)) <= 1e-9*math.Max(1, math.Abs(ratio))
+}
+
+func roundTo(v float64, decimals int) float64 {
+	pow := math.Pow10(decimals)
+	return math.Round(v*pow) / pow
+}
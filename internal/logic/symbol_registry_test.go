@@ -0,0 +1,143 @@
+package logic
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func testSymbol() models.Symbol {
+	return models.Symbol{
+		Symbol:         "BTC_USD",
+		Base:           "BTC",
+		Quote:          "USD",
+		TickSize:       0.5,
+		LotSize:        0.001,
+		PricePrecision: 1,
+		Status:         models.SymbolStatusTrading,
+	}
+}
+
+func TestLoadSymbolRegistry(t *testing.T) {
+	normalizer, err := LoadSymbolNormalizer("../testdata/symbol_aliases.json")
+	require.NoError(t, err, "Failed to load normalizer")
+
+	registry, err := LoadSymbolRegistry("../testdata/symbols.json", normalizer)
+	require.NoError(t, err, "Failed to load symbols")
+	require.Len(t, registry.List(), 3, "Expected 3 symbols")
+
+	_, err = LoadSymbolRegistry("../testdata/missing.json", normalizer)
+	require.Error(t, err, "Expected missing file to fail")
+}
+
+func TestLoadSymbolRegistry_Normalized(t *testing.T) {
+	normalizer, err := LoadSymbolNormalizer("../testdata/symbol_aliases.json")
+	require.NoError(t, err, "Failed to load normalizer")
+
+	path := filepath.Join(t.TempDir(), "symbols.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"symbol": "xbtusd", "status": "trading"},
+		{"symbol": "eth/usd", "status": "trading"}
+	]`), 0o600))
+	registry, err := LoadSymbolRegistry(path, normalizer)
+	require.NoError(t, err, "Failed to load symbols")
+	_, ok := registry.Get("BTC_USD")
+	require.True(t, ok, "Expected aliases to be resolved")
+	_, ok = registry.Get("ETH_USD")
+	require.True(t, ok, "Expected rules to be applied")
+
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"symbol": "XBTUSD", "status": "trading"},
+		{"symbol": "btc_usd", "status": "halted"}
+	]`), 0o600))
+	_, err = LoadSymbolRegistry(path, normalizer)
+	require.ErrorContains(t, err, "both BTC_USD once normalized", "Expected symbols colliding once normalized to fail")
+}
+
+func TestNewSymbolRegistry_Invalid(t *testing.T) {
+	invalid := testSymbol()
+	invalid.Status = "open"
+	_, err := NewSymbolRegistry([]models.Symbol{invalid})
+	require.Error(t, err, "Expected invalid status to fail")
+
+	_, err = NewSymbolRegistry([]models.Symbol{testSymbol(), testSymbol()})
+	require.Error(t, err, "Expected duplicate symbol to fail")
+}
+
+func TestSymbolRegistry_ValidateTrade(t *testing.T) {
+	open := NewOpenSymbolRegistry()
+	require.NoError(t, open.ValidateTrade(models.Trade{Symbol: "ANY"}), "Expected open registry to accept any symbol")
+	require.NoError(t, open.Upsert(testSymbol()), "Failed to upsert symbol")
+	require.NoError(t, open.ValidateTrade(models.Trade{Symbol: "ANY"}), "Expected open registry to keep accepting any symbol")
+
+	empty, err := NewSymbolRegistry(nil)
+	require.NoError(t, err, "Failed to create registry")
+	require.ErrorIs(t, empty.ValidateTrade(models.Trade{Symbol: "ANY"}), ErrUnknownSymbol, "Expected empty registry to reject unknown symbols")
+
+	registry, err := NewSymbolRegistry([]models.Symbol{testSymbol()})
+	require.NoError(t, err, "Failed to create registry")
+
+	testCases := []struct {
+		name     string
+		trade    models.Trade
+		expected error
+	}{
+		{"Valid", models.Trade{Symbol: "BTC_USD", Price: 100.5, Size: 0.123}, nil},
+		{"Float error", models.Trade{Symbol: "BTC_USD", Price: 0.1 + 0.2 + 99.7, Size: 0.3}, nil},
+		{"Unknown symbol", models.Trade{Symbol: "ETH_USD", Price: 100, Size: 1}, ErrUnknownSymbol},
+		{"Invalid tick", models.Trade{Symbol: "BTC_USD", Price: 100.25, Size: 1}, ErrInvalidTick},
+		{"Invalid lot", models.Trade{Symbol: "BTC_USD", Price: 100, Size: 0.0005}, ErrInvalidLot},
+	}
+	for _, tc := range testCases {
+		require.ErrorIsf(t, registry.ValidateTrade(tc.trade), tc.expected, "%s", tc.name)
+	}
+
+	halted := testSymbol()
+	halted.Status = models.SymbolStatusHalted
+	require.NoError(t, registry.Upsert(halted), "Failed to upsert symbol")
+	require.ErrorIs(t, registry.ValidateTrade(models.Trade{Symbol: "BTC_USD", Price: 100, Size: 1}), ErrSymbolNotTrading, "Expected halted symbol to reject trades")
+
+	require.True(t, registry.Delete("BTC_USD"), "Expected symbol to be deleted")
+	require.False(t, registry.Delete("BTC_USD"), "Expected symbol to be deleted only once")
+	require.ErrorIs(t, registry.ValidateTrade(models.Trade{Symbol: "BTC_USD", Price: 100, Size: 1}), ErrUnknownSymbol, "Expected registry to keep validating once emptied")
+}
+
+func TestSymbolRegistry_RoundCandles(t *testing.T) {
+	registry, err := NewSymbolRegistry([]models.Symbol{testSymbol()})
+	require.NoError(t, err, "Failed to create registry")
+
+	candles := models.CandleList{{Open: 100.04, High: 100.26, Low: 99.95, Close: 100.15, Volume: 10.123}}
+	registry.RoundCandles("BTC_USD", candles)
+	require.Equal(t, models.Candle{Open: 100.0, High: 100.3, Low: 100.0, Close: 100.2, Volume: 10.123}, candles[0], "Candle not rounded")
+
+	unrounded := models.CandleList{{Open: 100.04}}
+	registry.RoundCandles("ETH_USD", unrounded)
+	require.Equal(t, 100.04, unrounded[0].Open, "Unregistered symbol candle rounded")
+}
+
+func TestSymbolRegistry_Replace(t *testing.T) {
+	registry, err := NewSymbolRegistry([]models.Symbol{testSymbol()})
+	require.NoError(t, err, "Failed to create registry")
+
+	eth := testSymbol()
+	eth.Symbol = "ETH_USD"
+	reloaded, err := NewSymbolRegistry([]models.Symbol{eth})
+	require.NoError(t, err, "Failed to create registry")
+
+	before, after := registry.Replace(reloaded)
+	require.Equal(t, []models.Symbol{testSymbol()}, before, "Expected the symbols before replacing")
+	require.Equal(t, []models.Symbol{eth}, after, "Expected the symbols after replacing")
+	require.Equal(t, []models.Symbol{eth}, registry.List(), "Expected the symbols to be replaced")
+
+	// The registries don't share their symbols.
+	require.True(t, reloaded.Delete("ETH_USD"), "Expected symbol to be deleted")
+	_, ok := registry.Get("ETH_USD")
+	require.True(t, ok, "Expected the replaced registry to keep the symbol")
+
+	open := NewOpenSymbolRegistry()
+	open.Replace(registry)
+	require.ErrorIs(t, open.ValidateTrade(models.Trade{Symbol: "ANY"}), ErrUnknownSymbol, "Expected the replaced registry to start validating")
+}
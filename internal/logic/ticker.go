@@ -0,0 +1,249 @@
+package logic
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+)
+
+// TickerWindow is the rolling window a ticker summarizes.
+const TickerWindow = 24 * time.Hour
+
+// TickerTracker keeps the ticker of a symbol up to date as the candles of
+// the builder of its finest interval change, so that it's served in O(1).
+//
+// It holds the candles within the window with their running volume, and
+// the candles that may hold the high or the low once the older candles
+// leave the window. Candles leave the window as it moves, both as trades
+// are processed and when the ticker is served, each in O(1).
+//
+// When a candle older than the current one changes, on late trades,
+// cancels and corrections, or the current candle shrinks, the high and
+// low are found again from the candles within the window.
+type TickerTracker struct {
+	mtx     sync.Mutex
+	builder *CandleBuilder
+
+	// candles are those within the window, sorted by Timestamp.
+	candles []models.Candle
+	volume  float64
+	// highs are the candles with no higher high closing after them, and
+	// lows those with no lower low, sorted by Timestamp so that the first
+	// holds the high or the low of the window.
+	highs []models.Candle
+	lows  []models.Candle
+	// horizon is the start(ms) of the newest window, the candles
+	// closing at or before it have left the window.
+	horizon int64
+
+	lastPrice float64
+	hasLast   bool
+}
+
+// NewTickerTracker creates a tracker of the candles of the builder,
+// which must be updated through the tracker as its candles change.
+func NewTickerTracker(builder *CandleBuilder) *TickerTracker {
+	return &TickerTracker{builder: builder}
+}
+
+// Update rereads the candles the given trades belong to, after the
+// trades were processed, cancelled or corrected by the builder.
+func (t *TickerTracker) Update(trades ...models.Trade) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	// Read under the lock so an older read can't replace a newer one.
+	t.builder.mtx.RLock()
+	defer t.builder.mtx.RUnlock()
+
+	candleTimes := make([]int64, 0, len(trades))
+	for _, trade := range trades {
+		candleTimes = append(candleTimes, t.builder.candleTime(trade))
+	}
+	slices.Sort(candleTimes)
+
+	rescan := false
+	for _, ts := range slices.Compact(candleTimes) {
+		if !t.set(ts) {
+			rescan = true
+		}
+	}
+	if rescan {
+		t.rescan()
+	}
+	t.refreshLast()
+}
+
+// Reset rereads every candle within the window, after
+// the candles of the builder were replaced.
+func (t *TickerTracker) Reset() {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.builder.mtx.RLock()
+	defer t.builder.mtx.RUnlock()
+
+	t.candles = t.candles[:0]
+	t.volume = 0
+	if t.builder.current != nil {
+		t.horizon = max(t.horizon, t.builder.current.Timestamp-t.keep())
+		t.candles = append(t.candles, t.builder.candlesSince(t.horizon)...)
+	}
+	for _, c := range t.candles {
+		t.volume += c.Volume
+	}
+	t.rescan()
+	t.refreshLast()
+}
+
+// Ticker returns the ticker of the window ending at end(ms), usually the
+// time it's served, and false if the builder has no candles. The current
+// candle counts even though it closes after end. When no candle is within
+// the window the last price is kept with no change or volume.
+func (t *TickerTracker) Ticker(symbol string, end int64) (models.Ticker, bool) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if !t.hasLast {
+		return models.Ticker{}, false
+	}
+	// A candle closing at the window start covers the interval before it.
+	t.evict(end - TickerWindow.Milliseconds())
+
+	ticker := models.Ticker{
+		Symbol:    symbol,
+		Timestamp: end,
+		LastPrice: t.lastPrice,
+		Open:      t.lastPrice,
+		High:      t.lastPrice,
+		Low:       t.lastPrice,
+	}
+	if len(t.candles) > 0 {
+		ticker.Open = t.candles[0].Open
+		ticker.High = t.highs[0].High
+		ticker.Low = t.lows[0].Low
+		ticker.Volume = t.volume
+	}
+
+	ticker.Change = ticker.LastPrice - ticker.Open
+	if ticker.Open != 0 {
+		ticker.ChangePercent = ticker.Change / ticker.Open * 100
+	}
+	return ticker, true
+}
+
+// keep is how far(ms) before the close of the current candle candles
+// are kept, the window ending once the current candle opened.
+func (t *TickerTracker) keep() int64 {
+	return (TickerWindow + t.builder.p.Interval).Milliseconds()
+}
+
+// set rereads the candle of the builder closing at ts, returning
+// false if the high and low must be found again.
+//
+// The caller must hold both locks.
+func (t *TickerTracker) set(ts int64) bool {
+	if ts <= t.horizon {
+		return true
+	}
+
+	candle, exists := t.builder.closed[ts]
+	if current := t.builder.current; current != nil && current.Timestamp == ts {
+		candle, exists = *current, true
+	}
+	i, found := slices.BinarySearchFunc(t.candles, ts, func(c models.Candle, ts int64) int {
+		return cmp.Compare(c.Timestamp, ts)
+	})
+
+	switch {
+	case !exists && !found:
+		return true
+
+	case !exists:
+		t.volume -= t.candles[i].Volume
+		t.candles = slices.Delete(t.candles, i, i+1)
+		return false
+
+	case found:
+		old := t.candles[i]
+		t.volume += candle.Volume - old.Volume
+		t.candles[i] = candle
+		// The newest candle only growing is the common case of trades in order.
+		if i < len(t.candles)-1 || candle.High < old.High || candle.Low > old.Low {
+			return false
+		}
+		t.push(candle)
+
+	default:
+		t.volume += candle.Volume
+		t.candles = slices.Insert(t.candles, i, candle)
+		if i < len(t.candles)-1 {
+			return false
+		}
+		t.push(candle)
+		// Older candles leave the window as newer ones are added.
+		t.evict(ts - t.keep())
+	}
+	return true
+}
+
+// push adds the newest candle to the candidates for the high and low,
+// replacing the older version of it if any.
+func (t *TickerTracker) push(c models.Candle) {
+	for len(t.highs) > 0 {
+		last := t.highs[len(t.highs)-1]
+		if last.Timestamp != c.Timestamp && last.High > c.High {
+			break
+		}
+		t.highs = t.highs[:len(t.highs)-1]
+	}
+	t.highs = append(t.highs, c)
+
+	for len(t.lows) > 0 {
+		last := t.lows[len(t.lows)-1]
+		if last.Timestamp != c.Timestamp && last.Low < c.Low {
+			break
+		}
+		t.lows = t.lows[:len(t.lows)-1]
+	}
+	t.lows = append(t.lows, c)
+}
+
+// rescan finds the candidates for the high and low again.
+func (t *TickerTracker) rescan() {
+	t.highs = t.highs[:0]
+	t.lows = t.lows[:0]
+	for _, c := range t.candles {
+		t.push(c)
+	}
+}
+
+// evict removes the candles closing at or before start(ms).
+func (t *TickerTracker) evict(start int64) {
+	t.horizon = max(t.horizon, start)
+
+	for len(t.candles) > 0 && t.candles[0].Timestamp <= t.horizon {
+		oldest := t.candles[0]
+		if len(t.highs) > 0 && t.highs[0].Timestamp == oldest.Timestamp {
+			t.highs = t.highs[1:]
+		}
+		if len(t.lows) > 0 && t.lows[0].Timestamp == oldest.Timestamp {
+			t.lows = t.lows[1:]
+		}
+		t.volume -= oldest.Volume
+		t.candles = t.candles[1:]
+	}
+	if len(t.candles) == 0 {
+		// Don't keep the rounding errors of the running volume.
+		t.volume = 0
+	}
+}
+
+// refreshLast takes the last price from the current candle of the builder.
+func (t *TickerTracker) refreshLast() {
+	t.hasLast = t.builder.current != nil
+	if t.hasLast {
+		t.lastPrice = t.builder.current.Close
+	}
+}
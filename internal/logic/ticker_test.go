@@ -0,0 +1,182 @@
+package logic
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTickerTracker(t *testing.T) {
+	builder := NewBuilder(CandleBuilderParams{Interval: BuilderInterval1m})
+	tracker := NewTickerTracker(builder)
+
+	_, ok := tracker.Ticker("BTC_USD", 0)
+	require.False(t, ok, "Expected no ticker without candles")
+
+	end := time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC)
+	trade := func(id string, before time.Duration, price float64) models.Trade {
+		return models.Trade{TradeID: id, Timestamp: end.Add(-before).UnixMilli(), Price: price, Size: 1}
+	}
+	trades := []models.Trade{
+		// Its candle closes exactly at the window start so it's outside the window.
+		trade("1", TickerWindow+30*time.Second, 500),
+		trade("2", 23*time.Hour+50*time.Second, 100),
+		trade("3", 23*time.Hour+40*time.Second, 120),
+		trade("4", 23*time.Hour+30*time.Second, 90),
+		trade("5", 23*time.Hour+20*time.Second, 110),
+		trade("6", time.Hour+30*time.Second, 130),
+		trade("7", time.Hour+20*time.Second, 80),
+		trade("8", 30*time.Second, 120),
+	}
+	builder.ProcessTrades(trades)
+	tracker.Update(trades...)
+
+	ticker, ok := tracker.Ticker("BTC_USD", end.UnixMilli())
+	require.True(t, ok, "Expected ticker")
+	require.Equal(t, models.Ticker{
+		Symbol:        "BTC_USD",
+		Timestamp:     end.UnixMilli(),
+		LastPrice:     120,
+		Open:          100,
+		High:          130,
+		Low:           80,
+		Change:        20,
+		ChangePercent: 20,
+		Volume:        750,
+	}, ticker, "Ticker mismatch")
+
+	// The window ends at the time given rather than the newest candle.
+	later := end.Add(2 * time.Hour)
+	ticker, ok = tracker.Ticker("BTC_USD", later.UnixMilli())
+	require.True(t, ok, "Expected ticker")
+	require.Equal(t, models.Ticker{
+		Symbol:        "BTC_USD",
+		Timestamp:     later.UnixMilli(),
+		LastPrice:     120,
+		Open:          130,
+		High:          130,
+		Low:           80,
+		Change:        -10,
+		ChangePercent: -10.0 / 130 * 100,
+		Volume:        330,
+	}, ticker, "Ticker mismatch")
+
+	// Late trades and cancels update the candles within the window.
+	late := trade("9", time.Hour+10*time.Second, 140)
+	builder.ProcessTrades([]models.Trade{late})
+	tracker.Update(late)
+	ticker, _ = tracker.Ticker("BTC_USD", later.UnixMilli())
+	require.Equal(t, 140.0, ticker.High, "Expected the late trade to be the high")
+	require.Equal(t, 470.0, ticker.Volume, "Expected the late trade in the volume")
+
+	require.True(t, builder.CancelTrade(late), "Expected the late trade to be cancelled")
+	tracker.Update(late)
+	ticker, _ = tracker.Ticker("BTC_USD", later.UnixMilli())
+	require.Equal(t, 130.0, ticker.High, "Expected the cancelled trade to be removed from the high")
+	require.Equal(t, 330.0, ticker.Volume, "Expected the cancelled trade to be removed from the volume")
+
+	stale := end.Add(TickerWindow)
+	ticker, ok = tracker.Ticker("BTC_USD", stale.UnixMilli())
+	require.True(t, ok, "Expected ticker")
+	require.Equal(t, models.Ticker{
+		Symbol:    "BTC_USD",
+		Timestamp: stale.UnixMilli(),
+		LastPrice: 120,
+		Open:      120,
+		High:      120,
+		Low:       120,
+	}, ticker, "Expected the last price without candles in the window")
+}
+
+// computeTicker summarizes every candle of the builder
+// within the window ending at end(ms).
+func computeTicker(builder *CandleBuilder, end int64) models.Ticker {
+	candles := builder.GetCandles()
+	last := candles[len(candles)-1].Close
+	ticker := models.Ticker{Symbol: "BTC_USD", Timestamp: end, LastPrice: last, Open: last, High: last, Low: last}
+
+	first := true
+	for _, c := range candles {
+		if c.Timestamp <= end-TickerWindow.Milliseconds() {
+			continue
+		}
+		if first {
+			ticker.Open, ticker.High, ticker.Low = c.Open, c.High, c.Low
+			first = false
+		}
+		ticker.High = max(ticker.High, c.High)
+		ticker.Low = min(ticker.Low, c.Low)
+		ticker.Volume += c.Volume
+	}
+
+	ticker.Change = ticker.LastPrice - ticker.Open
+	if ticker.Open != 0 {
+		ticker.ChangePercent = ticker.Change / ticker.Open * 100
+	}
+	return ticker
+}
+
+func TestTickerTracker_MatchesFullRecompute(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	builder := NewBuilder(CandleBuilderParams{Interval: BuilderInterval1m})
+	tracker := NewTickerTracker(builder)
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	now := start
+	var processed []models.Trade
+	for i := range 20000 {
+		now += rng.Int63n(10 * time.Second.Milliseconds())
+		trade := models.Trade{
+			TradeID:   strconv.Itoa(i),
+			Timestamp: now,
+			Price:     100 + rng.Float64()*50,
+			Size:      rng.Float64(),
+		}
+
+		switch n := rng.Intn(100); {
+		case n < 5 && len(processed) > 0:
+			// Cancel a recent trade.
+			j := len(processed) - 1 - rng.Intn(min(len(processed), 50))
+			if builder.CancelTrade(processed[j]) {
+				tracker.Update(processed[j])
+			}
+			processed = append(processed[:j], processed[j+1:]...)
+			continue
+		case n < 15:
+			// A late trade.
+			trade.Timestamp -= rng.Int63n(time.Hour.Milliseconds())
+		}
+
+		builder.ProcessTrades([]models.Trade{trade})
+		tracker.Update(trade)
+		processed = append(processed, trade)
+
+		if i%100 == 0 && builder.HasCandles() {
+			// Served some time after the current candle opened.
+			end := now + rng.Int63n(time.Minute.Milliseconds())
+			ticker, ok := tracker.Ticker("BTC_USD", end)
+			require.True(t, ok, "Expected ticker")
+
+			expected := computeTicker(builder, end)
+			require.InDelta(t, expected.Volume, ticker.Volume, 1e-6, "Volume mismatch after %d trades", i)
+			ticker.Volume = expected.Volume
+			require.InDelta(t, expected.ChangePercent, ticker.ChangePercent, 1e-9, "Change percent mismatch after %d trades", i)
+			ticker.ChangePercent = expected.ChangePercent
+			require.Equal(t, expected, ticker, "Ticker mismatch after %d trades", i)
+		}
+	}
+
+	// A reset tracker rereads the same candles.
+	reset := NewTickerTracker(builder)
+	reset.Reset()
+	expected, _ := tracker.Ticker("BTC_USD", now)
+	ticker, ok := reset.Ticker("BTC_USD", now)
+	require.True(t, ok, "Expected ticker")
+	require.InDelta(t, expected.Volume, ticker.Volume, 1e-6, "Volume mismatch")
+	ticker.Volume = expected.Volume
+	require.Equal(t, expected, ticker, "Expected the reset tracker to match")
+}
@@ -0,0 +1,364 @@
+package logic
+
+import (
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+)
+
+// TradeCursor is the position of a trade in the TradeHistory.
+//
+// Trades are ordered by timestamp and then by TradeID, so a cursor
+// stays valid while newer or older trades are being ingested.
+type TradeCursor struct {
+	Timestamp int64
+	TradeID   string
+	// Before is set for cursors paging towards older trades.
+	Before bool
+}
+
+func cursorOf(t models.Trade) TradeCursor {
+	return TradeCursor{Timestamp: t.Timestamp, TradeID: t.TradeID}
+}
+
+func (c TradeCursor) less(o TradeCursor) bool {
+	if c.Timestamp != o.Timestamp {
+		return c.Timestamp < o.Timestamp
+	}
+	return c.TradeID < o.TradeID
+}
+
+// Encode returns the cursor as an opaque URL safe token.
+func (c TradeCursor) Encode() string {
+	dir := "a"
+	if c.Before {
+		dir = "b"
+	}
+	raw := dir + ":" + strconv.FormatInt(c.Timestamp, 10) + ":" + c.TradeID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ParseTradeCursor decodes a token created by TradeCursor.Encode.
+func ParseTradeCursor(token string) (TradeCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return TradeCursor{}, ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), ":", 3)
+	if len(parts) != 3 || (parts[0] != "a" && parts[0] != "b") {
+		return TradeCursor{}, ErrInvalidCursor
+	}
+
+	ts, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return TradeCursor{}, ErrInvalidCursor
+	}
+
+	return TradeCursor{Timestamp: ts, TradeID: parts[2], Before: parts[0] == "b"}, nil
+}
+
+// TradeQuery selects a page of trades for a symbol from the TradeHistory.
+type TradeQuery struct {
+	Symbol string
+	// From and To bound the trade timestamps(ms), From is
+	// inclusive and To is exclusive. Zero means unbounded.
+	From int64
+	To   int64
+	// After and Before are exclusive positions to page from.
+	//
+	// When only Before is set the page is made of the trades
+	// closest to it, otherwise of the trades closest to After.
+	After  *TradeCursor
+	Before *TradeCursor
+	// Limit is the maximum number of trades in the page.
+	Limit int
+}
+
+// TradePage is a page of trades sorted from oldest to newest.
+type TradePage struct {
+	Trades []models.Trade
+	// Next is set when there are newer trades matching the query.
+	Next *TradeCursor
+	// Prev is set when there are older trades matching the query.
+	Prev *TradeCursor
+}
+
+// IngestedTrade is a trade and its position in the order
+// the trades of its symbol were ingested.
+type IngestedTrade struct {
+	Seq   int
+	Trade models.Trade
+}
+
+// SymbolStats summarize the trades of a symbol in the TradeHistory.
+type SymbolStats struct {
+	FirstTradeTimestamp int64
+	LastTradeTimestamp  int64
+	TradeCount          int64
+	// LastPrice is the price of the newest trade.
+	LastPrice float64
+}
+
+// TradeHistory keeps every trade that has been pushed for all symbols
+// so they can be queried by time range.
+//
+// This is all in memory, in a production system this would be backed
+// by a persistent store like postgres or a time series database.
+type TradeHistory struct {
+	mtx sync.RWMutex
+	// Keyed by Symbol, sorted by TradeCursor order.
+	trades map[string][]models.Trade
+	// Keyed by TradeID.
+	byID map[string]models.Trade
+	// ingested is keyed by Symbol and holds the IDs of the trades
+	// in the order they were pushed, including removed trades.
+	ingested map[string][]string
+	// seqs is keyed by TradeID and holds the position of the trade
+	// in ingested, kept once the trade is removed.
+	seqs map[string]int
+}
+
+func NewTradeHistory() *TradeHistory {
+	return &TradeHistory{
+		trades:   make(map[string][]models.Trade),
+		byID:     make(map[string]models.Trade),
+		ingested: make(map[string][]string),
+		seqs:     make(map[string]int),
+	}
+}
+
+// PushTrades stores new trades. Trades with an ID
+// that is already in the history are ignored.
+func (h *TradeHistory) PushTrades(trades []models.Trade) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	for _, t := range trades {
+		if _, exists := h.byID[t.TradeID]; exists {
+			continue
+		}
+		h.insert(t)
+		h.seqs[t.TradeID] = len(h.ingested[t.Symbol])
+		h.ingested[t.Symbol] = append(h.ingested[t.Symbol], t.TradeID)
+	}
+}
+
+// Seq returns the position of the trade in the order the trades of its
+// symbol were pushed, which is kept once the trade is removed.
+func (h *TradeHistory) Seq(tradeID string) (int, bool) {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+
+	seq, ok := h.seqs[tradeID]
+	return seq, ok
+}
+
+// IngestedSince returns the trades of the symbol pushed from the given
+// position on, in the order they were pushed, and the position of the next
+// trade pushed. Corrected trades keep their position and removed trades
+// are skipped.
+func (h *TradeHistory) IngestedSince(symbol string, seq int) ([]IngestedTrade, int) {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+
+	ids := h.ingested[symbol]
+	trades := make([]IngestedTrade, 0, max(len(ids)-seq, 0))
+	for i := max(seq, 0); i < len(ids); i++ {
+		// A trade pushed again after being removed moved to its new position.
+		if t, ok := h.byID[ids[i]]; ok && h.seqs[ids[i]] == i {
+			trades = append(trades, IngestedTrade{Seq: i, Trade: t})
+		}
+	}
+	return trades, len(ids)
+}
+
+// Cursor returns the position of the trade with the given ID,
+// and the symbol of the trade as positions are per symbol.
+func (h *TradeHistory) Cursor(tradeID string) (TradeCursor, string, bool) {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+
+	t, exists := h.byID[tradeID]
+	if !exists {
+		return TradeCursor{}, "", false
+	}
+	return cursorOf(t), t.Symbol, true
+}
+
+// RemoveTrade removes the trade with the given ID.
+//
+// It returns false if the trade is not in the history.
+func (h *TradeHistory) RemoveTrade(tradeID string) bool {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	return h.remove(tradeID)
+}
+
+// ReplaceTrade replaces the trade with the same ID as the
+// given trade, moving it if its position changed.
+//
+// It returns false if the trade is not in the history.
+func (h *TradeHistory) ReplaceTrade(t models.Trade) bool {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	if !h.remove(t.TradeID) {
+		return false
+	}
+	h.insert(t)
+	return true
+}
+
+// Symbols returns every symbol with trades, sorted.
+func (h *TradeHistory) Symbols() []string {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+
+	symbols := make([]string, 0, len(h.trades))
+	for symbol, trades := range h.trades {
+		if len(trades) > 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Stats returns the summary of the trades of the symbol,
+// and false if there are none.
+func (h *TradeHistory) Stats(symbol string) (SymbolStats, bool) {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+
+	trades := h.trades[symbol]
+	if len(trades) == 0 {
+		return SymbolStats{}, false
+	}
+
+	first, last := trades[0], trades[len(trades)-1]
+	return SymbolStats{
+		FirstTradeTimestamp: first.Timestamp,
+		LastTradeTimestamp:  last.Timestamp,
+		TradeCount:          int64(len(trades)),
+		LastPrice:           last.Price,
+	}, true
+}
+
+// Query returns the page of trades selected by the query.
+func (h *TradeHistory) Query(q TradeQuery) TradePage {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+
+	trades := h.trades[q.Symbol]
+
+	// Narrow down to the trades matching the query bounds.
+	lo, hi := 0, len(trades)
+	if q.From != 0 {
+		lo = sort.Search(len(trades), func(i int) bool {
+			return trades[i].Timestamp >= q.From
+		})
+	}
+	if q.To != 0 {
+		hi = sort.Search(len(trades), func(i int) bool {
+			return trades[i].Timestamp >= q.To
+		})
+	}
+	if q.After != nil {
+		lo = max(lo, sort.Search(len(trades), func(i int) bool {
+			return q.After.less(cursorOf(trades[i]))
+		}))
+	}
+	if q.Before != nil {
+		hi = min(hi, sort.Search(len(trades), func(i int) bool {
+			return !cursorOf(trades[i]).less(*q.Before)
+		}))
+	}
+	if lo >= hi {
+		return TradePage{Trades: []models.Trade{}}
+	}
+
+	// Then take the page from the side being paged from.
+	start, end := lo, hi
+	if q.Limit > 0 && end-start > q.Limit {
+		if q.Before != nil && q.After == nil {
+			start = end - q.Limit
+		} else {
+			end = start + q.Limit
+		}
+	}
+
+	page := TradePage{Trades: make([]models.Trade, end-start)}
+	copy(page.Trades, trades[start:end])
+
+	// Cursors are only returned while there are
+	// more trades in that direction within the range.
+	inRange := func(t models.Trade) bool {
+		return (q.From == 0 || t.Timestamp >= q.From) && (q.To == 0 || t.Timestamp < q.To)
+	}
+	if end < len(trades) && inRange(trades[end]) {
+		next := cursorOf(trades[end-1])
+		page.Next = &next
+	}
+	if start > 0 && inRange(trades[start-1]) {
+		prev := cursorOf(trades[start])
+		prev.Before = true
+		page.Prev = &prev
+	}
+
+	return page
+}
+
+// insert adds the trade at its sorted position.
+//
+// The caller must hold the write lock.
+func (h *TradeHistory) insert(t models.Trade) {
+	trades := h.trades[t.Symbol]
+	key := cursorOf(t)
+
+	i := sort.Search(len(trades), func(i int) bool {
+		return key.less(cursorOf(trades[i]))
+	})
+	if i == len(trades) {
+		// The common case of trades arriving in order.
+		trades = append(trades, t)
+	} else {
+		trades = append(trades, models.Trade{})
+		copy(trades[i+1:], trades[i:])
+		trades[i] = t
+	}
+
+	h.trades[t.Symbol] = trades
+	h.byID[t.TradeID] = t
+}
+
+// remove deletes the trade with the given ID.
+//
+// The caller must hold the write lock.
+func (h *TradeHistory) remove(tradeID string) bool {
+	t, exists := h.byID[tradeID]
+	if !exists {
+		return false
+	}
+
+	trades := h.trades[t.Symbol]
+	key := cursorOf(t)
+	i := sort.Search(len(trades), func(i int) bool {
+		return !cursorOf(trades[i]).less(key)
+	})
+	if i < len(trades) && trades[i].TradeID == tradeID {
+		h.trades[t.Symbol] = append(trades[:i], trades[i+1:]...)
+	}
+
+	delete(h.byID, tradeID)
+	return true
+}
@@ -0,0 +1,160 @@
+package logic
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func historyTrades(n int) []models.Trade {
+	trades := make([]models.Trade, 0, n)
+	for i := 0; i < n; i++ {
+		trades = append(trades, models.Trade{
+			TradeID:   fmt.Sprintf("t%02d", i),
+			Symbol:    "BTC_USD",
+			Timestamp: int64(1000 + i*10),
+			Price:     100,
+			Size:      1,
+		})
+	}
+	return trades
+}
+
+func tradeIDs(trades []models.Trade) []string {
+	ids := make([]string, 0, len(trades))
+	for _, t := range trades {
+		ids = append(ids, t.TradeID)
+	}
+	return ids
+}
+
+func TestTradeHistory_PushTradesOutOfOrder(t *testing.T) {
+	history := NewTradeHistory()
+	trades := historyTrades(5)
+
+	history.PushTrades([]models.Trade{trades[3], trades[0], trades[4]})
+	history.PushTrades([]models.Trade{trades[2], trades[1], trades[1]})
+
+	page := history.Query(TradeQuery{Symbol: "BTC_USD"})
+	require.Equal(t, []string{"t00", "t01", "t02", "t03", "t04"}, tradeIDs(page.Trades), "Trades not sorted")
+	require.Nil(t, page.Next, "Expected no next cursor")
+	require.Nil(t, page.Prev, "Expected no prev cursor")
+}
+
+func TestTradeHistory_QueryPagination(t *testing.T) {
+	history := NewTradeHistory()
+	history.PushTrades(historyTrades(10))
+
+	// Range covers t02 to t07.
+	q := TradeQuery{Symbol: "BTC_USD", From: 1020, To: 1080, Limit: 4}
+
+	page := history.Query(q)
+	require.Equal(t, []string{"t02", "t03", "t04", "t05"}, tradeIDs(page.Trades), "First page mismatch")
+	require.NotNil(t, page.Next, "Expected next cursor")
+	require.Nil(t, page.Prev, "Expected no prev cursor at the range start")
+
+	// Trades ingested between pages don't shift the next page.
+	history.PushTrades([]models.Trade{{TradeID: "late", Symbol: "BTC_USD", Timestamp: 1021}})
+
+	cursor, err := ParseTradeCursor(page.Next.Encode())
+	require.NoError(t, err, "Failed to parse cursor")
+	q.After = &cursor
+	page = history.Query(q)
+	require.Equal(t, []string{"t06", "t07"}, tradeIDs(page.Trades), "Second page mismatch")
+	require.Nil(t, page.Next, "Expected no next cursor at the range end")
+	require.NotNil(t, page.Prev, "Expected prev cursor")
+
+	prev, err := ParseTradeCursor(page.Prev.Encode())
+	require.NoError(t, err, "Failed to parse cursor")
+	require.True(t, prev.Before, "Expected prev cursor to page backwards")
+	page = history.Query(TradeQuery{Symbol: "BTC_USD", From: 1020, To: 1080, Limit: 4, Before: &prev})
+	require.Equal(t, []string{"late", "t03", "t04", "t05"}, tradeIDs(page.Trades), "Previous page mismatch")
+}
+
+func TestTradeHistory_BeforeAfterTradeID(t *testing.T) {
+	history := NewTradeHistory()
+	history.PushTrades(historyTrades(10))
+
+	cursor, symbol, ok := history.Cursor("t05")
+	require.True(t, ok, "Expected cursor for t05")
+	require.Equal(t, "BTC_USD", symbol, "Expected the symbol of t05")
+
+	page := history.Query(TradeQuery{Symbol: "BTC_USD", Before: &cursor, Limit: 2})
+	require.Equal(t, []string{"t03", "t04"}, tradeIDs(page.Trades), "Before page mismatch")
+
+	page = history.Query(TradeQuery{Symbol: "BTC_USD", After: &cursor, Limit: 2})
+	require.Equal(t, []string{"t06", "t07"}, tradeIDs(page.Trades), "After page mismatch")
+
+	_, _, ok = history.Cursor("missing")
+	require.False(t, ok, "Expected no cursor for unknown trade")
+}
+
+func TestTradeHistory_RemoveAndReplace(t *testing.T) {
+	history := NewTradeHistory()
+	trades := historyTrades(3)
+	history.PushTrades(trades)
+
+	require.True(t, history.RemoveTrade("t01"), "Expected t01 to be removed")
+	require.False(t, history.RemoveTrade("t01"), "Expected t01 to be removed only once")
+
+	amended := trades[0]
+	amended.Timestamp = 2000
+	require.True(t, history.ReplaceTrade(amended), "Expected t00 to be replaced")
+
+	page := history.Query(TradeQuery{Symbol: "BTC_USD"})
+	require.Equal(t, []string{"t02", "t00"}, tradeIDs(page.Trades), "Trades mismatch after remove and replace")
+}
+
+func TestTradeHistory_IngestedSince(t *testing.T) {
+	history := NewTradeHistory()
+	trades := historyTrades(4)
+	history.PushTrades([]models.Trade{trades[2], trades[0], trades[3], trades[1]})
+
+	seq, ok := history.Seq("t03")
+	require.True(t, ok, "Expected t03 to have a seq")
+	require.Equal(t, 2, seq, "Expected the seq of the order trades were pushed")
+
+	require.True(t, history.RemoveTrade("t03"), "Expected t03 to be removed")
+	amended := trades[0]
+	amended.Timestamp = 2000
+	require.True(t, history.ReplaceTrade(amended), "Expected t00 to be replaced")
+
+	seq, ok = history.Seq("t03")
+	require.True(t, ok, "Expected removed trades to keep their seq")
+	require.Equal(t, 2, seq, "Seq mismatch")
+
+	ingested, next := history.IngestedSince("BTC_USD", 1)
+	require.Equal(t, []IngestedTrade{{Seq: 1, Trade: amended}, {Seq: 3, Trade: trades[1]}}, ingested,
+		"Expected the trades in the order they were pushed, skipping removed ones")
+	require.Equal(t, 4, next, "Expected the seq of the next trade pushed")
+}
+
+func TestParseTradeCursor_Invalid(t *testing.T) {
+	for _, token := range []string{"", "%%%", "eDoxOmE"} {
+		_, err := ParseTradeCursor(token)
+		require.ErrorIsf(t, err, ErrInvalidCursor, "Expected %q to be invalid", token)
+	}
+}
+
+func TestTradeHistory_Stats(t *testing.T) {
+	history := NewTradeHistory()
+
+	_, ok := history.Stats("BTC_USD")
+	require.False(t, ok, "Expected no stats without trades")
+
+	trades := historyTrades(3)
+	trades[2].Price = 105
+	history.PushTrades([]models.Trade{trades[2], trades[0], trades[1]})
+
+	stats, ok := history.Stats("BTC_USD")
+	require.True(t, ok, "Expected stats")
+	require.Equal(t, SymbolStats{
+		FirstTradeTimestamp: trades[0].Timestamp,
+		LastTradeTimestamp:  trades[2].Timestamp,
+		TradeCount:          3,
+		LastPrice:           105,
+	}, stats, "Stats mismatch")
+	require.Equal(t, []string{"BTC_USD"}, history.Symbols(), "Symbols mismatch")
+}
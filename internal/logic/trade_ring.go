@@ -0,0 +1,201 @@
+package logic
+
+import (
+	"sort"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+)
+
+// tradeRing is a fixed size ring buffer of trades
+// sorted from oldest to newest.
+//
+// The free slots sit on both ends of the stored trades,
+// so an out of order trade is inserted by shifting whichever
+// side of its position is shorter. Trades arriving in order,
+// the common case, are appended without shifting at all.
+type tradeRing struct {
+	buf []models.Trade
+	// start is the index in buf of the oldest trade.
+	start int
+	size  int
+}
+
+func newTradeRing(limit int) *tradeRing {
+	return &tradeRing{
+		buf: make([]models.Trade, limit),
+	}
+}
+
+// index returns the index in buf of the logical index i,
+// where 0 is the oldest trade.
+func (r *tradeRing) index(i int) int {
+	n := len(r.buf)
+	return ((r.start+i)%n + n) % n
+}
+
+func (r *tradeRing) at(i int) *models.Trade {
+	return &r.buf[r.index(i)]
+}
+
+// insert adds the trade at its sorted position, trades with equal
+// timestamps are kept in the order they were inserted. When the ring
+// is full the oldest trade is evicted to make room, unless the new
+// trade is not newer than it in which case the trade is skipped.
+func (r *tradeRing) insert(t models.Trade) {
+	if r.size == len(r.buf) {
+		if t.Timestamp <= r.at(0).Timestamp {
+			return // This new trade is too old to be in the ring
+		}
+		r.start = r.index(1)
+		r.size--
+	}
+
+	pos := sort.Search(r.size, func(i int) bool {
+		return r.at(i).Timestamp > t.Timestamp
+	})
+
+	if pos < r.size-pos {
+		r.shiftFront(0, pos)
+		r.start = r.index(-1)
+	} else {
+		r.shiftBack(pos, r.size)
+	}
+
+	*r.at(pos) = t
+	r.size++
+}
+
+// mergeBatchSize is the batch size from which trades are
+// merged into the ring rather than inserted one at a time.
+const mergeBatchSize = 32
+
+// insertBatch adds the trades to the ring as if each were inserted in order.
+//
+// Large batches are sorted and merged with the trades of the ring newer than
+// the oldest of the batch, which are none for a batch arriving in order, so
+// an out of order batch doesn't shift the stored trades once per trade. Only
+// the newest trades that fit in the ring are kept, trades with equal
+// timestamps stay in the order they were inserted.
+func (r *tradeRing) insertBatch(trades []models.Trade) {
+	if len(trades) < mergeBatchSize {
+		for _, t := range trades {
+			r.insert(t)
+		}
+		return
+	}
+
+	byTimestamp := func(trades []models.Trade) func(i, j int) bool {
+		return func(i, j int) bool {
+			return trades[i].Timestamp < trades[j].Timestamp
+		}
+	}
+	sorted := trades
+	if !sort.SliceIsSorted(trades, byTimestamp(trades)) {
+		sorted = make([]models.Trade, len(trades))
+		copy(sorted, trades)
+		sort.SliceStable(sorted, byTimestamp(sorted))
+	}
+
+	// Take the trades the batch has to be merged with off the ring.
+	pos := sort.Search(r.size, func(i int) bool {
+		return r.at(i).Timestamp > sorted[0].Timestamp
+	})
+	newer := make([]models.Trade, r.size-pos)
+	for i := range newer {
+		newer[i] = *r.at(pos + i)
+		*r.at(pos + i) = models.Trade{}
+	}
+	r.size = pos
+
+	i, j := 0, 0
+	for i < len(newer) || j < len(sorted) {
+		// On equal timestamps the batch trade was inserted later.
+		if i < len(newer) && (j == len(sorted) || newer[i].Timestamp <= sorted[j].Timestamp) {
+			r.push(newer[i])
+			i++
+		} else {
+			r.push(sorted[j])
+			j++
+		}
+	}
+}
+
+// push appends a trade that is not older than any in the ring,
+// evicting the oldest trade when the ring is full.
+func (r *tradeRing) push(t models.Trade) {
+	if r.size == len(r.buf) {
+		r.start = r.index(1)
+		r.size--
+	}
+	*r.at(r.size) = t
+	r.size++
+}
+
+// remove deletes the trade with the given ID.
+//
+// It returns false if the trade is not in the ring.
+func (r *tradeRing) remove(tradeID string) bool {
+	for pos := 0; pos < r.size; pos++ {
+		if r.at(pos).TradeID != tradeID {
+			continue
+		}
+
+		r.shiftFront(pos+1, r.size)
+		*r.at(r.size - 1) = models.Trade{}
+		r.size--
+		return true
+	}
+	return false
+}
+
+// shiftFront moves the trades at the logical indexes
+// [from, to) one slot towards the front.
+func (r *tradeRing) shiftFront(from, to int) {
+	for from < to {
+		// Copy the largest run that doesn't wrap around
+		// the end of buf on either side.
+		src, dst := r.index(from), r.index(from-1)
+		n := min(to-from, len(r.buf)-src, len(r.buf)-dst)
+		copy(r.buf[dst:dst+n], r.buf[src:src+n])
+		from += n
+	}
+}
+
+// shiftBack moves the trades at the logical indexes
+// [from, to) one slot towards the back.
+func (r *tradeRing) shiftBack(from, to int) {
+	for from < to {
+		// Copy the largest run, ending at the last trade to move,
+		// that doesn't wrap around the start of buf on either side.
+		src, dst := r.index(to-1), r.index(to)
+		n := min(to-from, src+1, dst+1)
+		copy(r.buf[dst-n+1:dst+1], r.buf[src-n+1:src+1])
+		to -= n
+	}
+}
+
+// resize changes the capacity of the ring to limit,
+// keeping the newest trades that fit.
+func (r *tradeRing) resize(limit int) {
+	trades := r.trades(limit)
+
+	r.buf = make([]models.Trade, limit)
+	copy(r.buf, trades)
+	r.start = 0
+	r.size = len(trades)
+}
+
+// trades returns a copy of up to limit of the newest trades in the ring
+// from oldest to newest, or all of them if limit is not positive.
+func (r *tradeRing) trades(limit int) []models.Trade {
+	n := r.size
+	if limit > 0 && limit < n {
+		n = limit
+	}
+
+	trades := make([]models.Trade, n)
+	for i := range trades {
+		trades[i] = *r.at(r.size - n + i)
+	}
+	return trades
+}
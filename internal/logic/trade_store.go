@@ -12,18 +12,21 @@ type TradeStoreParams struct {
 	//
 	// Defaults to 50.
 	CacheLimit int
+	// SymbolCacheLimits overrides the CacheLimit
+	// for specific symbols, keyed by Symbol.
+	SymbolCacheLimits map[string]int
 }
 
 // TradeStore is a cache of trade events
 // that have been pushed for all symbols.
 //
-// The trade stores up to the limit specified
-// by the CacheLimit argument for each symbol.
+// The trade stores up to the limit specified by the
+// CacheLimit argument, or its override, for each symbol.
 type TradeStore struct {
 	p   TradeStoreParams
 	mtx sync.RWMutex
 	// Keyed by Symbol
-	trades map[string][]models.Trade
+	trades map[string]*tradeRing
 }
 
 func NewTradeStore(p TradeStoreParams) *TradeStore {
@@ -33,7 +36,7 @@ func NewTradeStore(p TradeStoreParams) *TradeStore {
 
 	return &TradeStore{
 		p:      p,
-		trades: make(map[string][]models.Trade),
+		trades: make(map[string]*tradeRing),
 		mtx:    sync.RWMutex{},
 	}
 }
@@ -43,55 +46,131 @@ func (store *TradeStore) PushTrades(trades []models.Trade) {
 	store.mtx.Lock()
 	defer store.mtx.Unlock()
 
+	if len(trades) == 0 {
+		return
+	}
+
+	// Batches are usually for a single symbol so
+	// avoid grouping the trades when that's the case.
+	single := true
 	for _, trade := range trades {
-		symbolTrades := store.trades[trade.Symbol]
-		if symbolTrades == nil {
-			symbolTrades = make([]models.Trade, 0, store.p.CacheLimit)
+		if trade.Symbol != trades[0].Symbol {
+			single = false
+			break
 		}
+	}
+	if single {
+		store.ring(trades[0].Symbol).insertBatch(trades)
+		return
+	}
 
-		// If cache is full and new trade is older than or equal to the oldest cached trade, skip.
-		if len(symbolTrades) == store.p.CacheLimit && trade.Timestamp <= symbolTrades[0].Timestamp {
-			continue // This new trade is too old to be in the cache
-		}
+	bySymbol := make(map[string][]models.Trade)
+	for _, trade := range trades {
+		bySymbol[trade.Symbol] = append(bySymbol[trade.Symbol], trade)
+	}
+	for symbol, symbolTrades := range bySymbol {
+		store.ring(symbol).insertBatch(symbolTrades)
+	}
+}
 
-		// Find the correct position to insert the new trade to maintain sorted order (oldest to front, newest to back)
-		inserted := false
-		for i := 0; i < len(symbolTrades); i++ {
-			if trade.Timestamp < symbolTrades[i].Timestamp {
-				// Insert trade before symbolTrades[i]
-				symbolTrades = append(symbolTrades[:i], append([]models.Trade{trade}, symbolTrades[i:]...)...)
-				inserted = true
-				break
-			}
-		}
+// ring returns the ring buffer of the symbol, creating it if needed.
+//
+// The caller must hold the write lock.
+func (store *TradeStore) ring(symbol string) *tradeRing {
+	ring, exists := store.trades[symbol]
+	if !exists {
+		ring = newTradeRing(store.limit(symbol))
+		store.trades[symbol] = ring
+	}
+	return ring
+}
 
-		if !inserted {
-			// If newTrade is the newest or the slice was empty, append it to the end
-			symbolTrades = append(symbolTrades, trade)
-		}
+// Limit returns the maximum number of trades kept for the symbol.
+func (store *TradeStore) Limit(symbol string) int {
+	store.mtx.RLock()
+	defer store.mtx.RUnlock()
+
+	return store.limit(symbol)
+}
+
+func (store *TradeStore) limit(symbol string) int {
+	if limit, ok := store.p.SymbolCacheLimits[symbol]; ok && limit > 0 {
+		return limit
+	}
+	return store.p.CacheLimit
+}
+
+// SetLimits replaces the CacheLimit and SymbolCacheLimits, resizing
+// the caches of the symbols whose limit changed. Shrinking a cache
+// evicts its oldest trades.
+func (store *TradeStore) SetLimits(cacheLimit int, symbolCacheLimits map[string]int) {
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+
+	if cacheLimit <= 0 {
+		cacheLimit = 50
+	}
+	store.p.CacheLimit = cacheLimit
+	store.p.SymbolCacheLimits = symbolCacheLimits
 
-		// Trim the slice to the CacheLimit if it exceeds it, keeping the newest trades at the end.
-		if len(symbolTrades) > store.p.CacheLimit {
-			symbolTrades = symbolTrades[len(symbolTrades)-store.p.CacheLimit:]
+	for symbol, ring := range store.trades {
+		if limit := store.limit(symbol); limit != len(ring.buf) {
+			ring.resize(limit)
 		}
+	}
+}
+
+// Sizes returns the number of trades cached for each symbol.
+func (store *TradeStore) Sizes() map[string]int {
+	store.mtx.RLock()
+	defer store.mtx.RUnlock()
+
+	sizes := make(map[string]int, len(store.trades))
+	for symbol, ring := range store.trades {
+		sizes[symbol] = ring.size
+	}
+	return sizes
+}
+
+// RemoveTrade removes a cached trade of the symbol by its ID.
+//
+// It returns false if the trade is not in the cache.
+func (store *TradeStore) RemoveTrade(symbol, tradeID string) bool {
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+
+	ring, exists := store.trades[symbol]
+	return exists && ring.remove(tradeID)
+}
+
+// ReplaceTrade replaces the cached trade with the same ID as the given
+// trade, moving it if its timestamp changed.
+//
+// It returns false if the trade is not in the cache.
+func (store *TradeStore) ReplaceTrade(trade models.Trade) bool {
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
 
-		store.trades[trade.Symbol] = symbolTrades
+	ring, exists := store.trades[trade.Symbol]
+	if !exists || !ring.remove(trade.TradeID) {
+		return false
 	}
+	ring.insert(trade)
+	return true
 }
 
-// GetTrades retrieves the cached trades for a given symbol.
+// GetTrades retrieves up to limit of the newest cached trades for a given symbol,
+// or all of them if limit is not positive.
 // It returns a slice of trades, sorted from oldest to newest.
-func (store *TradeStore) GetTrades(symbol string) []models.Trade {
+func (store *TradeStore) GetTrades(symbol string, limit int) []models.Trade {
 	store.mtx.RLock()
 	defer store.mtx.RUnlock()
 
-	symbolTrades, exists := store.trades[symbol]
-	if !exists || len(symbolTrades) == 0 {
+	ring, exists := store.trades[symbol]
+	if !exists || ring.size == 0 {
 		return []models.Trade{}
 	}
 
-	// Return a copy to prevent external modification of the cached slice.
-	tradesCopy := make([]models.Trade, len(symbolTrades))
-	copy(tradesCopy, symbolTrades)
-	return tradesCopy
+	// Return a copy to prevent external modification of the cached trades.
+	return ring.trades(limit)
 }
@@ -0,0 +1,250 @@
+package logic
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func storeTrades(timestamps ...int64) []models.Trade {
+	trades := make([]models.Trade, 0, len(timestamps))
+	for i, ts := range timestamps {
+		trades = append(trades, models.Trade{
+			TradeID:   fmt.Sprintf("t%d", i),
+			Symbol:    "BTC_USD",
+			Timestamp: ts,
+		})
+	}
+	return trades
+}
+
+func storeTimestamps(trades []models.Trade) []int64 {
+	timestamps := make([]int64, 0, len(trades))
+	for _, t := range trades {
+		timestamps = append(timestamps, t.Timestamp)
+	}
+	return timestamps
+}
+
+func TestNewTradeStore_DefaultLimit(t *testing.T) {
+	store := NewTradeStore(TradeStoreParams{})
+	require.Equal(t, 50, store.p.CacheLimit, "CacheLimit default mismatch")
+	require.Empty(t, store.GetTrades("BTC_USD", 0), "Expected no trades")
+}
+
+func TestTradeStore_PushTrades(t *testing.T) {
+	store := NewTradeStore(TradeStoreParams{CacheLimit: 4})
+
+	store.PushTrades(storeTrades(30, 10, 20))
+	require.Equal(t, []int64{10, 20, 30}, storeTimestamps(store.GetTrades("BTC_USD", 0)), "Trades not sorted")
+
+	// Evicts the oldest trades once full, skipping trades older than the cache.
+	store.PushTrades(storeTrades(25, 5, 40, 15))
+	require.Equal(t, []int64{20, 25, 30, 40}, storeTimestamps(store.GetTrades("BTC_USD", 0)), "Trades not evicted")
+}
+
+func TestTradeStore_SymbolCacheLimits(t *testing.T) {
+	store := NewTradeStore(TradeStoreParams{
+		CacheLimit:        2,
+		SymbolCacheLimits: map[string]int{"BTC_USD": 4},
+	})
+	require.Equal(t, 4, store.Limit("BTC_USD"), "Override limit mismatch")
+	require.Equal(t, 2, store.Limit("ETH_USD"), "Default limit mismatch")
+
+	trades := storeTrades(10, 20, 30, 40, 50)
+	store.PushTrades(trades)
+	for i := range trades {
+		trades[i].Symbol = "ETH_USD"
+	}
+	store.PushTrades(trades)
+
+	require.Equal(t, []int64{20, 30, 40, 50}, storeTimestamps(store.GetTrades("BTC_USD", 0)), "Override limit not applied")
+	require.Equal(t, []int64{40, 50}, storeTimestamps(store.GetTrades("ETH_USD", 0)), "Default limit not applied")
+	require.Equal(t, []int64{40, 50}, storeTimestamps(store.GetTrades("BTC_USD", 2)), "Expected the newest trades")
+}
+
+func TestTradeStore_SetLimits(t *testing.T) {
+	store := NewTradeStore(TradeStoreParams{CacheLimit: 4})
+
+	trades := storeTrades(10, 20, 30, 40)
+	store.PushTrades(trades)
+	for i := range trades {
+		trades[i].Symbol = "ETH_USD"
+	}
+	store.PushTrades(trades)
+
+	store.SetLimits(2, map[string]int{"ETH_USD": 6})
+	require.Equal(t, 2, store.Limit("BTC_USD"), "Default limit not replaced")
+	require.Equal(t, 6, store.Limit("ETH_USD"), "Override limit not replaced")
+	require.Equal(t, []int64{30, 40}, storeTimestamps(store.GetTrades("BTC_USD", 0)), "Expected the oldest trades to be evicted")
+	require.Equal(t, []int64{10, 20, 30, 40}, storeTimestamps(store.GetTrades("ETH_USD", 0)), "Expected the trades to be kept")
+
+	// Grown caches keep accepting trades up to the new limit.
+	for i := range trades {
+		trades[i].TradeID += "-late"
+		trades[i].Timestamp += 40
+	}
+	store.PushTrades(trades)
+	require.Equal(t, []int64{30, 40, 50, 60, 70, 80}, storeTimestamps(store.GetTrades("ETH_USD", 0)), "Grown limit not applied")
+}
+
+func TestTradeStore_EqualTimestamps(t *testing.T) {
+	store := NewTradeStore(TradeStoreParams{CacheLimit: 10})
+
+	trades := storeTrades(10, 20, 10, 20)
+	store.PushTrades(trades)
+
+	cached := store.GetTrades("BTC_USD", 0)
+	require.Equal(t, []string{"t0", "t2", "t1", "t3"}, tradeIDs(cached), "Equal timestamps not kept in arrival order")
+}
+
+func TestTradeStore_RemoveAndReplace(t *testing.T) {
+	store := NewTradeStore(TradeStoreParams{CacheLimit: 4})
+
+	trades := storeTrades(10, 20, 30, 40)
+	store.PushTrades(trades)
+
+	require.True(t, store.RemoveTrade("BTC_USD", "t1"), "Expected t1 to be removed")
+	require.False(t, store.RemoveTrade("BTC_USD", "t1"), "Expected t1 to be removed only once")
+	require.False(t, store.RemoveTrade("ETH_USD", "t0"), "Expected unknown symbol to fail")
+
+	amended := trades[0]
+	amended.Timestamp = 50
+	require.True(t, store.ReplaceTrade(amended), "Expected t0 to be replaced")
+	require.Equal(t, []string{"t2", "t3", "t0"}, tradeIDs(store.GetTrades("BTC_USD", 0)), "Trades mismatch after remove and replace")
+}
+
+// TestTradeStore_MatchesSortedSlice checks the ring buffer against a
+// plain sorted slice trimmed to the cache limit.
+func TestTradeStore_MatchesSortedSlice(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for _, limit := range []int{1, 7, 64} {
+		store := NewTradeStore(TradeStoreParams{CacheLimit: limit})
+		expected := make([]models.Trade, 0, limit+1)
+
+		for i := 0; i < 2000; i++ {
+			trade := models.Trade{
+				TradeID:   fmt.Sprintf("t%d", i),
+				Symbol:    "BTC_USD",
+				Timestamp: int64(i + rng.Intn(200)),
+			}
+			store.PushTrades([]models.Trade{trade})
+
+			if len(expected) == limit && trade.Timestamp <= expected[0].Timestamp {
+				continue
+			}
+			pos := sort.Search(len(expected), func(j int) bool {
+				return expected[j].Timestamp > trade.Timestamp
+			})
+			expected = append(expected[:pos], append([]models.Trade{trade}, expected[pos:]...)...)
+			if len(expected) > limit {
+				expected = expected[1:]
+			}
+		}
+
+		require.Equalf(t, tradeIDs(expected), tradeIDs(store.GetTrades("BTC_USD", 0)), "Mismatch at limit %d", limit)
+	}
+}
+
+func TestTradeStore_BatchMatchesSortedSlice(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for _, limit := range []int{1, 50, 1000} {
+		store := NewTradeStore(TradeStoreParams{CacheLimit: limit})
+		var all []models.Trade
+
+		for batch := 0; batch < 5; batch++ {
+			trades := make([]models.Trade, 0, 600)
+			for _, i := range rng.Perm(600) {
+				trades = append(trades, models.Trade{
+					TradeID:   fmt.Sprintf("b%d_%d", batch, i),
+					Symbol:    "BTC_USD",
+					Timestamp: int64(batch*300 + i),
+				})
+			}
+			store.PushTrades(trades)
+			all = append(all, trades...)
+		}
+
+		sort.SliceStable(all, func(i, j int) bool {
+			return all[i].Timestamp < all[j].Timestamp
+		})
+		expected := all[max(0, len(all)-limit):]
+
+		require.Equalf(t, tradeIDs(expected), tradeIDs(store.GetTrades("BTC_USD", 0)), "Mismatch at limit %d", limit)
+	}
+}
+
+func benchmarkPushTrades(b *testing.B, order func(trades []models.Trade)) {
+	for _, limit := range []int{1_000, 10_000, 100_000} {
+		b.Run(fmt.Sprintf("limit=%d", limit), func(b *testing.B) {
+			ts := make([]int64, 2*limit)
+			for i := range ts {
+				ts[i] = int64(i)
+			}
+			trades := storeTrades(ts...)
+			order(trades)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				store := NewTradeStore(TradeStoreParams{CacheLimit: limit})
+				store.PushTrades(trades)
+			}
+		})
+	}
+}
+
+func BenchmarkTradeStore_PushTradesInOrder(b *testing.B) {
+	benchmarkPushTrades(b, func([]models.Trade) {})
+}
+
+func BenchmarkTradeStore_PushTradesReverseOrder(b *testing.B) {
+	benchmarkPushTrades(b, func(trades []models.Trade) {
+		for i, j := 0, len(trades)-1; i < j; i, j = i+1, j-1 {
+			trades[i], trades[j] = trades[j], trades[i]
+		}
+	})
+}
+
+func BenchmarkTradeStore_PushTradesRandomOrder(b *testing.B) {
+	benchmarkPushTrades(b, func(trades []models.Trade) {
+		rng := rand.New(rand.NewSource(1))
+		rng.Shuffle(len(trades), func(i, j int) {
+			trades[i], trades[j] = trades[j], trades[i]
+		})
+	})
+}
+
+func BenchmarkTradeStore_PushBatchesFull(b *testing.B) {
+	for _, limit := range []int{1_000, 10_000, 100_000} {
+		for _, size := range []int{mergeBatchSize, 100} {
+			b.Run(fmt.Sprintf("limit=%d/batch=%d", limit, size), func(b *testing.B) {
+				ts := make([]int64, limit)
+				for i := range ts {
+					ts[i] = int64(i)
+				}
+				store := NewTradeStore(TradeStoreParams{CacheLimit: limit})
+				store.PushTrades(storeTrades(ts...))
+
+				batch := storeTrades(make([]int64, size)...)
+				next := int64(limit)
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					for j := range batch {
+						batch[j].Timestamp = next
+						next++
+					}
+					store.PushTrades(batch)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,301 @@
+// Package metrics implements the few metric types the server exposes in
+// the Prometheus text exposition format.
+//
+// This is deliberately small, with a full Prometheus client available
+// this would be replaced by it.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefBuckets are the default histogram buckets in seconds,
+// suited to request latencies.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type collector interface {
+	write(w io.Writer)
+}
+
+// Registry holds metrics and writes them in registration order.
+type Registry struct {
+	mtx        sync.Mutex
+	collectors []collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(c collector) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.collectors = append(r.collectors, c)
+}
+
+// WriteText writes every metric in the text exposition format.
+//
+// Writes are serialized so collectors can share state between them.
+func (r *Registry) WriteText(w io.Writer) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	buf := bufio.NewWriter(w)
+	for _, c := range r.collectors {
+		c.write(buf)
+	}
+	buf.Flush()
+}
+
+// Handler serves the metrics of the registry.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		r.WriteText(w)
+	})
+}
+
+type desc struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+}
+
+func (d desc) writeHeader(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.name, d.help, d.name, d.typ)
+}
+
+// writeSample writes a sample of the metric, extra is an
+// additional label pair like the le of histogram buckets.
+func (d desc) writeSample(w io.Writer, suffix string, labelValues []string, extra string, v float64) {
+	io.WriteString(w, d.name+suffix)
+
+	pairs := make([]string, 0, len(labelValues)+1)
+	for i, name := range d.labels {
+		pairs = append(pairs, name+`="`+escape(labelValues[i])+`"`)
+	}
+	if extra != "" {
+		pairs = append(pairs, extra)
+	}
+	if len(pairs) > 0 {
+		io.WriteString(w, "{"+strings.Join(pairs, ",")+"}")
+	}
+
+	io.WriteString(w, " "+formatFloat(v)+"\n")
+}
+
+func (d desc) key(labelValues []string) string {
+	if len(labelValues) != len(d.labels) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", d.name, len(d.labels), len(labelValues)))
+	}
+	return strings.Join(labelValues, "\xff")
+}
+
+func escape(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `"`, `\"`)
+	return strings.ReplaceAll(v, "\n", `\n`)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// series holds a value per set of label values.
+type series[T any] struct {
+	mtx    sync.Mutex
+	values map[string]*T
+	labels map[string][]string
+}
+
+func (s *series[T]) get(key string, labelValues []string, init func() *T) *T {
+	if s.values == nil {
+		s.values = make(map[string]*T)
+		s.labels = make(map[string][]string)
+	}
+	v, ok := s.values[key]
+	if !ok {
+		v = init()
+		s.values[key] = v
+		s.labels[key] = append([]string{}, labelValues...)
+	}
+	return v
+}
+
+// sorted returns the keys in order so the output is stable.
+func (s *series[T]) sorted() []string {
+	keys := make([]string, 0, len(s.values))
+	for k := range s.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CounterVec is a counter partitioned by label values.
+type CounterVec struct {
+	desc
+	series series[float64]
+}
+
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{desc: desc{name: name, help: help, typ: "counter", labels: labels}}
+	r.register(c)
+	return c
+}
+
+// Add increases the counter of the label values by v, which must not be negative.
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	c.series.mtx.Lock()
+	defer c.series.mtx.Unlock()
+
+	*c.series.get(c.key(labelValues), labelValues, func() *float64 { return new(float64) }) += v
+}
+
+// Inc increases the counter of the label values by one.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.series.mtx.Lock()
+	defer c.series.mtx.Unlock()
+
+	c.writeHeader(w)
+	for _, key := range c.series.sorted() {
+		c.writeSample(w, "", c.series.labels[key], "", *c.series.values[key])
+	}
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// HistogramVec is a histogram partitioned by label values.
+type HistogramVec struct {
+	desc
+	buckets []float64
+	series  series[histogram]
+}
+
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{
+		desc:    desc{name: name, help: help, typ: "histogram", labels: labels},
+		buckets: append([]float64{}, buckets...),
+	}
+	sort.Float64s(h.buckets)
+	r.register(h)
+	return h
+}
+
+// Observe adds a value to the histogram of the label values.
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	h.series.mtx.Lock()
+	defer h.series.mtx.Unlock()
+
+	hist := h.series.get(h.key(labelValues), labelValues, func() *histogram {
+		return &histogram{counts: make([]uint64, len(h.buckets))}
+	})
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		hist.counts[i]++
+	}
+	hist.count++
+	hist.sum += v
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.series.mtx.Lock()
+	defer h.series.mtx.Unlock()
+
+	h.writeHeader(w)
+	for _, key := range h.series.sorted() {
+		hist, labelValues := h.series.values[key], h.series.labels[key]
+
+		// Buckets are cumulative.
+		var cumulative uint64
+		for i, le := range h.buckets {
+			cumulative += hist.counts[i]
+			h.writeSample(w, "_bucket", labelValues, `le="`+formatFloat(le)+`"`, float64(cumulative))
+		}
+		h.writeSample(w, "_bucket", labelValues, `le="+Inf"`, float64(hist.count))
+		h.writeSample(w, "_sum", labelValues, "", hist.sum)
+		h.writeSample(w, "_count", labelValues, "", float64(hist.count))
+	}
+}
+
+// GaugeVec is a gauge partitioned by label values.
+type GaugeVec struct {
+	desc
+	series series[float64]
+}
+
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{desc: desc{name: name, help: help, typ: "gauge", labels: labels}}
+	r.register(g)
+	return g
+}
+
+// Set sets the gauge of the label values to v.
+func (g *GaugeVec) Set(v float64, labelValues ...string) {
+	g.series.mtx.Lock()
+	defer g.series.mtx.Unlock()
+
+	*g.series.get(g.key(labelValues), labelValues, func() *float64 { return new(float64) }) = v
+}
+
+func (g *GaugeVec) write(w io.Writer) {
+	g.series.mtx.Lock()
+	defer g.series.mtx.Unlock()
+
+	g.writeHeader(w)
+	for _, key := range g.series.sorted() {
+		g.writeSample(w, "", g.series.labels[key], "", *g.series.values[key])
+	}
+}
+
+// Emit reports the value of a metric for the given label values.
+type Emit func(v float64, labelValues ...string)
+
+// funcCollector collects its samples when the metrics are written,
+// for values that are already tracked elsewhere.
+type funcCollector struct {
+	desc
+	collect func(emit Emit)
+}
+
+// NewGaugeFunc registers a gauge whose samples are collected by calling
+// collect whenever the metrics are written.
+func (r *Registry) NewGaugeFunc(name, help string, labels []string, collect func(emit Emit)) {
+	r.register(&funcCollector{desc: desc{name: name, help: help, typ: "gauge", labels: labels}, collect: collect})
+}
+
+// NewCounterFunc is like NewGaugeFunc for values that only increase.
+func (r *Registry) NewCounterFunc(name, help string, labels []string, collect func(emit Emit)) {
+	r.register(&funcCollector{desc: desc{name: name, help: help, typ: "counter", labels: labels}, collect: collect})
+}
+
+func (f *funcCollector) write(w io.Writer) {
+	f.writeHeader(w)
+	f.collect(func(v float64, labelValues ...string) {
+		f.key(labelValues)
+		f.writeSample(w, "", labelValues, "", v)
+	})
+}
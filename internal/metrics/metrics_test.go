@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeString(r *Registry) string {
+	var b strings.Builder
+	r.WriteText(&b)
+	return b.String()
+}
+
+func TestCounterVec(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("trades_total", "Trades.", "symbol", "result")
+	c.Inc("ETH", "accepted")
+	c.Add(2, "BTC", "accepted")
+	c.Inc("BTC", "accepted")
+
+	require.Equal(t, `# HELP trades_total Trades.
+# TYPE trades_total counter
+trades_total{symbol="BTC",result="accepted"} 3
+trades_total{symbol="ETH",result="accepted"} 1
+`, writeString(r), "Counter output mismatch")
+}
+
+func TestHistogramVec(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogramVec("latency_seconds", "Latency.", []float64{1, 0.1}, "route")
+	h.Observe(0.05, "/a")
+	h.Observe(0.1, "/a")
+	h.Observe(3, "/a")
+
+	require.Equal(t, `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/a",le="0.1"} 2
+latency_seconds_bucket{route="/a",le="1"} 2
+latency_seconds_bucket{route="/a",le="+Inf"} 3
+latency_seconds_sum{route="/a"} 3.15
+latency_seconds_count{route="/a"} 3
+`, writeString(r), "Histogram output mismatch")
+}
+
+func TestGauges(t *testing.T) {
+	r := NewRegistry()
+	g := r.NewGaugeVec("last_seconds", "Last.", "symbol")
+	g.Set(5, `A"B`)
+	g.Set(7, `A"B`)
+	r.NewGaugeFunc("goroutines", "Goroutines.", nil, func(emit Emit) {
+		emit(12)
+	})
+
+	require.Equal(t, `# HELP last_seconds Last.
+# TYPE last_seconds gauge
+last_seconds{symbol="A\"B"} 7
+# HELP goroutines Goroutines.
+# TYPE goroutines gauge
+goroutines 12
+`, writeString(r), "Gauge output mismatch")
+}
+
+func TestLabelCountMismatch(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("trades_total", "Trades.", "symbol")
+	require.Panics(t, func() { c.Inc() }, "Expected missing label values to panic")
+}
@@ -21,6 +21,73 @@ type Trade struct {
 //easyjson:json
 type TradeList []Trade
 
+// TradeCancel is a request to bust a previously ingested trade.
+type TradeCancel struct {
+	TradeID string `json:"trade_id"`
+}
+
+// Symbol is the metadata of a tradable symbol
+// defined in the symbol registry.
+type Symbol struct {
+	// TickSize is the increment prices must be a multiple of.
+	TickSize float64 `json:"tick_size"`
+	// LotSize is the increment sizes must be a multiple of.
+	LotSize float64 `json:"lot_size"`
+	// PricePrecision is the number of decimals prices are rounded to.
+	PricePrecision int    `json:"price_precision"`
+	Symbol         string `json:"symbol"`
+	Base           string `json:"base"`
+	Quote          string `json:"quote"`
+	// Status is one of the SymbolStatus values.
+	Status string `json:"status"`
+}
+
+const (
+	// SymbolStatusTrading symbols accept trades.
+	SymbolStatusTrading = "trading"
+	// SymbolStatusHalted symbols reject trades until trading resumes.
+	SymbolStatusHalted = "halted"
+	// SymbolStatusDelisted symbols reject trades permanently.
+	SymbolStatusDelisted = "delisted"
+)
+
+//easyjson:json
+type SymbolList []Symbol
+
+// SymbolInfo describes a symbol known to the server, either
+// registered in the symbol registry or seen on an ingested trade.
+type SymbolInfo struct {
+	LastPrice           float64 `json:"last_price"`
+	FirstTradeTimestamp int64   `json:"first_trade_timestamp"`
+	LastTradeTimestamp  int64   `json:"last_trade_timestamp"`
+	TradeCount          int64   `json:"trade_count"`
+	Symbol              string  `json:"symbol"`
+	// Intervals are the candle intervals that have candles.
+	Intervals []string `json:"intervals"`
+	// Metadata is set for symbols in the symbol registry.
+	Metadata *Symbol `json:"metadata,omitempty"`
+}
+
+//easyjson:json
+type SymbolInfoList []SymbolInfo
+
+// SymbolNormalization configures how venue specific
+// symbols are mapped to canonical symbols.
+type SymbolNormalization struct {
+	// Aliases maps a symbol to its canonical symbol.
+	Aliases map[string]string `json:"aliases"`
+	// Rules are tried in order and the first matching rule
+	// rewrites the symbol.
+	Rules []NormalizationRule `json:"rules"`
+}
+
+// NormalizationRule rewrites symbols matching the Match regular
+// expression to Replace, which may refer to submatches like ${1}.
+type NormalizationRule struct {
+	Match   string `json:"match"`
+	Replace string `json:"replace"`
+}
+
 // Candle represents an OHLC candle containing summary
 // data about all trades occuring within a window of time
 type Candle struct {
@@ -41,7 +108,118 @@ type Candle struct {
 	// This makes it easy to check if the candle
 	// should be updated or not based on the current time
 	Timestamp int64 `json:"timestamp"`
+	// Revision is the number of times the candle
+	// was amended by late trades after it closed.
+	Revision int64 `json:"revision"`
 }
 
 //easyjson:json
 type CandleList []Candle
+
+// LateTradeStats are the late trade counters for
+// the candles of a symbol at an interval.
+type LateTradeStats struct {
+	Late      int64  `json:"late"`
+	Dropped   int64  `json:"dropped"`
+	Corrected int64  `json:"corrected"`
+	Symbol    string `json:"symbol"`
+	Interval  string `json:"interval"`
+}
+
+//easyjson:json
+type LateTradeStatsList []LateTradeStats
+
+// Ticker summarizes the trading of a symbol over
+// a rolling 24 hour window.
+type Ticker struct {
+	LastPrice float64 `json:"last_price"`
+	Open      float64 `json:"open"`
+	High      float64 `json:"high"`
+	Low       float64 `json:"low"`
+	// Change is LastPrice - Open, ChangePercent is
+	// the same relative to Open.
+	Change        float64 `json:"change"`
+	ChangePercent float64 `json:"change_percent"`
+	// Volume is in Quote/Notional like the candle volume.
+	Volume float64 `json:"volume"`
+	// Timestamp is the close time(ms) of the window,
+	// the time the ticker was served.
+	Timestamp int64  `json:"timestamp"`
+	Symbol    string `json:"symbol"`
+}
+
+//easyjson:json
+type TickerList []Ticker
+
+// IndicatorPoint are the values of an indicator for a candle.
+type IndicatorPoint struct {
+	// Values are in the order of the series Fields.
+	Values []float64 `json:"values"`
+	// Timestamp is the close time(ms) of the candle.
+	Timestamp int64 `json:"timestamp"`
+	// Live is set for the current candle, whose
+	// values change until the candle closes.
+	Live bool `json:"live"`
+}
+
+// IndicatorSeries are the values of a technical indicator
+// over the candles of a symbol at an interval.
+type IndicatorSeries struct {
+	Params   []float64        `json:"params"`
+	Fields   []string         `json:"fields"`
+	Points   []IndicatorPoint `json:"points"`
+	Name     string           `json:"name"`
+	Symbol   string           `json:"symbol"`
+	Interval string           `json:"interval"`
+}
+
+const (
+	// RebuildStatusRunning jobs are replaying trades.
+	RebuildStatusRunning = "running"
+	// RebuildStatusDone jobs have swapped in the rebuilt candles.
+	RebuildStatusDone = "done"
+)
+
+// RebuildJob is the progress of rebuilding the
+// candles of a symbol from its trade history.
+type RebuildJob struct {
+	// From and To are the range(ms) of candles rebuilt, aligned
+	// to the interval, a To of 0 rebuilds up to the newest candle.
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+	// TradesTotal is the number of trades to replay,
+	// of which TradesProcessed have been replayed.
+	TradesTotal     int64 `json:"trades_total"`
+	TradesProcessed int64 `json:"trades_processed"`
+	// StartedAt and FinishedAt are wall clock times(ms).
+	StartedAt  int64  `json:"started_at"`
+	FinishedAt int64  `json:"finished_at,omitempty"`
+	ID         string `json:"id"`
+	Symbol     string `json:"symbol"`
+	// Interval is empty when every interval is rebuilt.
+	Interval string `json:"interval,omitempty"`
+	// Status is one of the RebuildStatus values.
+	Status string `json:"status"`
+}
+
+//easyjson:json
+type RebuildJobList []RebuildJob
+
+// BuildInfo describes the running binary.
+type BuildInfo struct {
+	// Version is the module version, "(devel)" for local builds.
+	Version string `json:"version"`
+	// Revision is the VCS revision built, Modified
+	// is set when the working tree had changes.
+	Revision string `json:"revision,omitempty"`
+	// BuildTime is set at link time, falling back
+	// to the commit time of the revision.
+	BuildTime string `json:"build_time,omitempty"`
+	GoVersion string `json:"go_version"`
+	Modified  bool   `json:"modified,omitempty"`
+}
+
+// ConfigReload lists the settings changed by reloading the config.
+type ConfigReload struct {
+	Changes []string `json:"changes"`
+}
@@ -83,7 +83,73 @@ func (v *TradeList) UnmarshalJSON(data []byte) error {
 func (v *TradeList) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels(l, v)
 }
-func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels1(in *jlexer.Lexer, out *Trade) {
+func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels1(in *jlexer.Lexer, out *TradeCancel) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "trade_id":
+			out.TradeID = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels1(out *jwriter.Writer, in TradeCancel) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"trade_id\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.TradeID))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v TradeCancel) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v TradeCancel) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *TradeCancel) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *TradeCancel) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels1(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels2(in *jlexer.Lexer, out *Trade) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -122,7 +188,7 @@ func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels1(in *
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels1(out *jwriter.Writer, in Trade) {
+func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels2(out *jwriter.Writer, in Trade) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -157,27 +223,27 @@ func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels1(out
 // MarshalJSON supports json.Marshaler interface
 func (v Trade) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels1(&w, v)
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels2(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Trade) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels1(w, v)
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels2(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Trade) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels1(&r, v)
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels2(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Trade) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels1(l, v)
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels2(l, v)
 }
-func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels2(in *jlexer.Lexer, out *CandleList) {
+func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels3(in *jlexer.Lexer, out *TickerList) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		in.Skip()
@@ -186,15 +252,15 @@ func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels2(in *
 		in.Delim('[')
 		if *out == nil {
 			if !in.IsDelim(']') {
-				*out = make(CandleList, 0, 1)
+				*out = make(TickerList, 0, 0)
 			} else {
-				*out = CandleList{}
+				*out = TickerList{}
 			}
 		} else {
 			*out = (*out)[:0]
 		}
 		for !in.IsDelim(']') {
-			var v4 Candle
+			var v4 Ticker
 			(v4).UnmarshalEasyJSON(in)
 			*out = append(*out, v4)
 			in.WantComma()
@@ -205,7 +271,7 @@ func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels2(in *
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels2(out *jwriter.Writer, in CandleList) {
+func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels3(out *jwriter.Writer, in TickerList) {
 	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
 		out.RawString("null")
 	} else {
@@ -221,29 +287,29 @@ func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels2(out
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v CandleList) MarshalJSON() ([]byte, error) {
+func (v TickerList) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels2(&w, v)
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels3(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v CandleList) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels2(w, v)
+func (v TickerList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels3(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *CandleList) UnmarshalJSON(data []byte) error {
+func (v *TickerList) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels2(&r, v)
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels3(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *CandleList) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels2(l, v)
+func (v *TickerList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels3(l, v)
 }
-func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels3(in *jlexer.Lexer, out *Candle) {
+func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels4(in *jlexer.Lexer, out *Ticker) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -262,18 +328,24 @@ func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels3(in *
 			continue
 		}
 		switch key {
+		case "last_price":
+			out.LastPrice = float64(in.Float64())
 		case "open":
 			out.Open = float64(in.Float64())
 		case "high":
 			out.High = float64(in.Float64())
 		case "low":
 			out.Low = float64(in.Float64())
-		case "close":
-			out.Close = float64(in.Float64())
+		case "change":
+			out.Change = float64(in.Float64())
+		case "change_percent":
+			out.ChangePercent = float64(in.Float64())
 		case "volume":
 			out.Volume = float64(in.Float64())
 		case "timestamp":
 			out.Timestamp = int64(in.Int64())
+		case "symbol":
+			out.Symbol = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -284,13 +356,18 @@ func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels3(in *
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels3(out *jwriter.Writer, in Candle) {
+func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels4(out *jwriter.Writer, in Ticker) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"open\":"
+		const prefix string = ",\"last_price\":"
 		out.RawString(prefix[1:])
+		out.Float64(float64(in.LastPrice))
+	}
+	{
+		const prefix string = ",\"open\":"
+		out.RawString(prefix)
 		out.Float64(float64(in.Open))
 	}
 	{
@@ -304,9 +381,14 @@ func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels3(out
 		out.Float64(float64(in.Low))
 	}
 	{
-		const prefix string = ",\"close\":"
+		const prefix string = ",\"change\":"
 		out.RawString(prefix)
-		out.Float64(float64(in.Close))
+		out.Float64(float64(in.Change))
+	}
+	{
+		const prefix string = ",\"change_percent\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.ChangePercent))
 	}
 	{
 		const prefix string = ",\"volume\":"
@@ -318,29 +400,1660 @@ func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels3(out
 		out.RawString(prefix)
 		out.Int64(int64(in.Timestamp))
 	}
+	{
+		const prefix string = ",\"symbol\":"
+		out.RawString(prefix)
+		out.String(string(in.Symbol))
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v Candle) MarshalJSON() ([]byte, error) {
+func (v Ticker) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels3(&w, v)
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels4(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v Candle) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels3(w, v)
+func (v Ticker) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels4(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *Candle) UnmarshalJSON(data []byte) error {
+func (v *Ticker) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels3(&r, v)
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels4(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *Candle) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels3(l, v)
+func (v *Ticker) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels4(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels5(in *jlexer.Lexer, out *SymbolNormalization) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "aliases":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.Aliases = make(map[string]string)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v7 string
+					v7 = string(in.String())
+					(out.Aliases)[key] = v7
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "rules":
+			if in.IsNull() {
+				in.Skip()
+				out.Rules = nil
+			} else {
+				in.Delim('[')
+				if out.Rules == nil {
+					if !in.IsDelim(']') {
+						out.Rules = make([]NormalizationRule, 0, 2)
+					} else {
+						out.Rules = []NormalizationRule{}
+					}
+				} else {
+					out.Rules = (out.Rules)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v8 NormalizationRule
+					(v8).UnmarshalEasyJSON(in)
+					out.Rules = append(out.Rules, v8)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels5(out *jwriter.Writer, in SymbolNormalization) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"aliases\":"
+		out.RawString(prefix[1:])
+		if in.Aliases == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v9First := true
+			for v9Name, v9Value := range in.Aliases {
+				if v9First {
+					v9First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v9Name))
+				out.RawByte(':')
+				out.String(string(v9Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"rules\":"
+		out.RawString(prefix)
+		if in.Rules == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v10, v11 := range in.Rules {
+				if v10 > 0 {
+					out.RawByte(',')
+				}
+				(v11).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v SymbolNormalization) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels5(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v SymbolNormalization) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels5(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *SymbolNormalization) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels5(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *SymbolNormalization) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels5(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels6(in *jlexer.Lexer, out *SymbolList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(SymbolList, 0, 0)
+			} else {
+				*out = SymbolList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v12 Symbol
+			(v12).UnmarshalEasyJSON(in)
+			*out = append(*out, v12)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels6(out *jwriter.Writer, in SymbolList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v13, v14 := range in {
+			if v13 > 0 {
+				out.RawByte(',')
+			}
+			(v14).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v SymbolList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels6(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v SymbolList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels6(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *SymbolList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels6(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *SymbolList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels6(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels7(in *jlexer.Lexer, out *SymbolInfoList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(SymbolInfoList, 0, 0)
+			} else {
+				*out = SymbolInfoList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v15 SymbolInfo
+			(v15).UnmarshalEasyJSON(in)
+			*out = append(*out, v15)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels7(out *jwriter.Writer, in SymbolInfoList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v16, v17 := range in {
+			if v16 > 0 {
+				out.RawByte(',')
+			}
+			(v17).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v SymbolInfoList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels7(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v SymbolInfoList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels7(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *SymbolInfoList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels7(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *SymbolInfoList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels7(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels8(in *jlexer.Lexer, out *SymbolInfo) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "last_price":
+			out.LastPrice = float64(in.Float64())
+		case "first_trade_timestamp":
+			out.FirstTradeTimestamp = int64(in.Int64())
+		case "last_trade_timestamp":
+			out.LastTradeTimestamp = int64(in.Int64())
+		case "trade_count":
+			out.TradeCount = int64(in.Int64())
+		case "symbol":
+			out.Symbol = string(in.String())
+		case "intervals":
+			if in.IsNull() {
+				in.Skip()
+				out.Intervals = nil
+			} else {
+				in.Delim('[')
+				if out.Intervals == nil {
+					if !in.IsDelim(']') {
+						out.Intervals = make([]string, 0, 4)
+					} else {
+						out.Intervals = []string{}
+					}
+				} else {
+					out.Intervals = (out.Intervals)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v18 string
+					v18 = string(in.String())
+					out.Intervals = append(out.Intervals, v18)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "metadata":
+			if in.IsNull() {
+				in.Skip()
+				out.Metadata = nil
+			} else {
+				if out.Metadata == nil {
+					out.Metadata = new(Symbol)
+				}
+				(*out.Metadata).UnmarshalEasyJSON(in)
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels8(out *jwriter.Writer, in SymbolInfo) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"last_price\":"
+		out.RawString(prefix[1:])
+		out.Float64(float64(in.LastPrice))
+	}
+	{
+		const prefix string = ",\"first_trade_timestamp\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.FirstTradeTimestamp))
+	}
+	{
+		const prefix string = ",\"last_trade_timestamp\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.LastTradeTimestamp))
+	}
+	{
+		const prefix string = ",\"trade_count\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.TradeCount))
+	}
+	{
+		const prefix string = ",\"symbol\":"
+		out.RawString(prefix)
+		out.String(string(in.Symbol))
+	}
+	{
+		const prefix string = ",\"intervals\":"
+		out.RawString(prefix)
+		if in.Intervals == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v19, v20 := range in.Intervals {
+				if v19 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v20))
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.Metadata != nil {
+		const prefix string = ",\"metadata\":"
+		out.RawString(prefix)
+		(*in.Metadata).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v SymbolInfo) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels8(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v SymbolInfo) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels8(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *SymbolInfo) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels8(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *SymbolInfo) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels8(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels9(in *jlexer.Lexer, out *Symbol) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "tick_size":
+			out.TickSize = float64(in.Float64())
+		case "lot_size":
+			out.LotSize = float64(in.Float64())
+		case "price_precision":
+			out.PricePrecision = int(in.Int())
+		case "symbol":
+			out.Symbol = string(in.String())
+		case "base":
+			out.Base = string(in.String())
+		case "quote":
+			out.Quote = string(in.String())
+		case "status":
+			out.Status = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels9(out *jwriter.Writer, in Symbol) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"tick_size\":"
+		out.RawString(prefix[1:])
+		out.Float64(float64(in.TickSize))
+	}
+	{
+		const prefix string = ",\"lot_size\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.LotSize))
+	}
+	{
+		const prefix string = ",\"price_precision\":"
+		out.RawString(prefix)
+		out.Int(int(in.PricePrecision))
+	}
+	{
+		const prefix string = ",\"symbol\":"
+		out.RawString(prefix)
+		out.String(string(in.Symbol))
+	}
+	{
+		const prefix string = ",\"base\":"
+		out.RawString(prefix)
+		out.String(string(in.Base))
+	}
+	{
+		const prefix string = ",\"quote\":"
+		out.RawString(prefix)
+		out.String(string(in.Quote))
+	}
+	{
+		const prefix string = ",\"status\":"
+		out.RawString(prefix)
+		out.String(string(in.Status))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Symbol) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels9(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Symbol) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels9(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Symbol) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels9(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Symbol) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels9(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels10(in *jlexer.Lexer, out *RebuildJobList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(RebuildJobList, 0, 0)
+			} else {
+				*out = RebuildJobList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v21 RebuildJob
+			(v21).UnmarshalEasyJSON(in)
+			*out = append(*out, v21)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels10(out *jwriter.Writer, in RebuildJobList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v22, v23 := range in {
+			if v22 > 0 {
+				out.RawByte(',')
+			}
+			(v23).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v RebuildJobList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels10(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v RebuildJobList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels10(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *RebuildJobList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels10(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *RebuildJobList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels10(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels11(in *jlexer.Lexer, out *RebuildJob) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "from":
+			out.From = int64(in.Int64())
+		case "to":
+			out.To = int64(in.Int64())
+		case "trades_total":
+			out.TradesTotal = int64(in.Int64())
+		case "trades_processed":
+			out.TradesProcessed = int64(in.Int64())
+		case "started_at":
+			out.StartedAt = int64(in.Int64())
+		case "finished_at":
+			out.FinishedAt = int64(in.Int64())
+		case "id":
+			out.ID = string(in.String())
+		case "symbol":
+			out.Symbol = string(in.String())
+		case "interval":
+			out.Interval = string(in.String())
+		case "status":
+			out.Status = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels11(out *jwriter.Writer, in RebuildJob) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"from\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.From))
+	}
+	{
+		const prefix string = ",\"to\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.To))
+	}
+	{
+		const prefix string = ",\"trades_total\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.TradesTotal))
+	}
+	{
+		const prefix string = ",\"trades_processed\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.TradesProcessed))
+	}
+	{
+		const prefix string = ",\"started_at\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.StartedAt))
+	}
+	if in.FinishedAt != 0 {
+		const prefix string = ",\"finished_at\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.FinishedAt))
+	}
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix)
+		out.String(string(in.ID))
+	}
+	{
+		const prefix string = ",\"symbol\":"
+		out.RawString(prefix)
+		out.String(string(in.Symbol))
+	}
+	if in.Interval != "" {
+		const prefix string = ",\"interval\":"
+		out.RawString(prefix)
+		out.String(string(in.Interval))
+	}
+	{
+		const prefix string = ",\"status\":"
+		out.RawString(prefix)
+		out.String(string(in.Status))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v RebuildJob) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels11(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v RebuildJob) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels11(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *RebuildJob) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels11(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *RebuildJob) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels11(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels12(in *jlexer.Lexer, out *NormalizationRule) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "match":
+			out.Match = string(in.String())
+		case "replace":
+			out.Replace = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels12(out *jwriter.Writer, in NormalizationRule) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"match\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Match))
+	}
+	{
+		const prefix string = ",\"replace\":"
+		out.RawString(prefix)
+		out.String(string(in.Replace))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v NormalizationRule) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels12(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v NormalizationRule) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels12(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *NormalizationRule) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels12(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *NormalizationRule) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels12(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels13(in *jlexer.Lexer, out *LateTradeStatsList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(LateTradeStatsList, 0, 1)
+			} else {
+				*out = LateTradeStatsList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v24 LateTradeStats
+			(v24).UnmarshalEasyJSON(in)
+			*out = append(*out, v24)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels13(out *jwriter.Writer, in LateTradeStatsList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v25, v26 := range in {
+			if v25 > 0 {
+				out.RawByte(',')
+			}
+			(v26).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LateTradeStatsList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels13(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LateTradeStatsList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels13(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LateTradeStatsList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels13(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LateTradeStatsList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels13(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels14(in *jlexer.Lexer, out *LateTradeStats) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "late":
+			out.Late = int64(in.Int64())
+		case "dropped":
+			out.Dropped = int64(in.Int64())
+		case "corrected":
+			out.Corrected = int64(in.Int64())
+		case "symbol":
+			out.Symbol = string(in.String())
+		case "interval":
+			out.Interval = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels14(out *jwriter.Writer, in LateTradeStats) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"late\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Late))
+	}
+	{
+		const prefix string = ",\"dropped\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Dropped))
+	}
+	{
+		const prefix string = ",\"corrected\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Corrected))
+	}
+	{
+		const prefix string = ",\"symbol\":"
+		out.RawString(prefix)
+		out.String(string(in.Symbol))
+	}
+	{
+		const prefix string = ",\"interval\":"
+		out.RawString(prefix)
+		out.String(string(in.Interval))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LateTradeStats) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels14(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LateTradeStats) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels14(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LateTradeStats) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels14(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LateTradeStats) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels14(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels15(in *jlexer.Lexer, out *IndicatorSeries) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "params":
+			if in.IsNull() {
+				in.Skip()
+				out.Params = nil
+			} else {
+				in.Delim('[')
+				if out.Params == nil {
+					if !in.IsDelim(']') {
+						out.Params = make([]float64, 0, 8)
+					} else {
+						out.Params = []float64{}
+					}
+				} else {
+					out.Params = (out.Params)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v27 float64
+					v27 = float64(in.Float64())
+					out.Params = append(out.Params, v27)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "fields":
+			if in.IsNull() {
+				in.Skip()
+				out.Fields = nil
+			} else {
+				in.Delim('[')
+				if out.Fields == nil {
+					if !in.IsDelim(']') {
+						out.Fields = make([]string, 0, 4)
+					} else {
+						out.Fields = []string{}
+					}
+				} else {
+					out.Fields = (out.Fields)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v28 string
+					v28 = string(in.String())
+					out.Fields = append(out.Fields, v28)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "points":
+			if in.IsNull() {
+				in.Skip()
+				out.Points = nil
+			} else {
+				in.Delim('[')
+				if out.Points == nil {
+					if !in.IsDelim(']') {
+						out.Points = make([]IndicatorPoint, 0, 1)
+					} else {
+						out.Points = []IndicatorPoint{}
+					}
+				} else {
+					out.Points = (out.Points)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v29 IndicatorPoint
+					(v29).UnmarshalEasyJSON(in)
+					out.Points = append(out.Points, v29)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "name":
+			out.Name = string(in.String())
+		case "symbol":
+			out.Symbol = string(in.String())
+		case "interval":
+			out.Interval = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels15(out *jwriter.Writer, in IndicatorSeries) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"params\":"
+		out.RawString(prefix[1:])
+		if in.Params == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v30, v31 := range in.Params {
+				if v30 > 0 {
+					out.RawByte(',')
+				}
+				out.Float64(float64(v31))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"fields\":"
+		out.RawString(prefix)
+		if in.Fields == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v32, v33 := range in.Fields {
+				if v32 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v33))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"points\":"
+		out.RawString(prefix)
+		if in.Points == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v34, v35 := range in.Points {
+				if v34 > 0 {
+					out.RawByte(',')
+				}
+				(v35).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix)
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"symbol\":"
+		out.RawString(prefix)
+		out.String(string(in.Symbol))
+	}
+	{
+		const prefix string = ",\"interval\":"
+		out.RawString(prefix)
+		out.String(string(in.Interval))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v IndicatorSeries) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels15(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v IndicatorSeries) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels15(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *IndicatorSeries) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels15(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *IndicatorSeries) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels15(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels16(in *jlexer.Lexer, out *IndicatorPoint) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "values":
+			if in.IsNull() {
+				in.Skip()
+				out.Values = nil
+			} else {
+				in.Delim('[')
+				if out.Values == nil {
+					if !in.IsDelim(']') {
+						out.Values = make([]float64, 0, 8)
+					} else {
+						out.Values = []float64{}
+					}
+				} else {
+					out.Values = (out.Values)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v36 float64
+					v36 = float64(in.Float64())
+					out.Values = append(out.Values, v36)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "timestamp":
+			out.Timestamp = int64(in.Int64())
+		case "live":
+			out.Live = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels16(out *jwriter.Writer, in IndicatorPoint) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"values\":"
+		out.RawString(prefix[1:])
+		if in.Values == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v37, v38 := range in.Values {
+				if v37 > 0 {
+					out.RawByte(',')
+				}
+				out.Float64(float64(v38))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"timestamp\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Timestamp))
+	}
+	{
+		const prefix string = ",\"live\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Live))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v IndicatorPoint) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels16(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v IndicatorPoint) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels16(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *IndicatorPoint) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels16(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *IndicatorPoint) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels16(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels17(in *jlexer.Lexer, out *ConfigReload) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "changes":
+			if in.IsNull() {
+				in.Skip()
+				out.Changes = nil
+			} else {
+				in.Delim('[')
+				if out.Changes == nil {
+					if !in.IsDelim(']') {
+						out.Changes = make([]string, 0, 4)
+					} else {
+						out.Changes = []string{}
+					}
+				} else {
+					out.Changes = (out.Changes)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v39 string
+					v39 = string(in.String())
+					out.Changes = append(out.Changes, v39)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels17(out *jwriter.Writer, in ConfigReload) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"changes\":"
+		out.RawString(prefix[1:])
+		if in.Changes == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v40, v41 := range in.Changes {
+				if v40 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v41))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ConfigReload) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels17(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ConfigReload) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels17(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ConfigReload) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels17(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ConfigReload) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels17(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels18(in *jlexer.Lexer, out *CandleList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(CandleList, 0, 1)
+			} else {
+				*out = CandleList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v42 Candle
+			(v42).UnmarshalEasyJSON(in)
+			*out = append(*out, v42)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels18(out *jwriter.Writer, in CandleList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v43, v44 := range in {
+			if v43 > 0 {
+				out.RawByte(',')
+			}
+			(v44).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v CandleList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels18(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v CandleList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels18(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *CandleList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels18(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *CandleList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels18(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels19(in *jlexer.Lexer, out *Candle) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "open":
+			out.Open = float64(in.Float64())
+		case "high":
+			out.High = float64(in.Float64())
+		case "low":
+			out.Low = float64(in.Float64())
+		case "close":
+			out.Close = float64(in.Float64())
+		case "volume":
+			out.Volume = float64(in.Float64())
+		case "timestamp":
+			out.Timestamp = int64(in.Int64())
+		case "revision":
+			out.Revision = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels19(out *jwriter.Writer, in Candle) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"open\":"
+		out.RawString(prefix[1:])
+		out.Float64(float64(in.Open))
+	}
+	{
+		const prefix string = ",\"high\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.High))
+	}
+	{
+		const prefix string = ",\"low\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Low))
+	}
+	{
+		const prefix string = ",\"close\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Close))
+	}
+	{
+		const prefix string = ",\"volume\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Volume))
+	}
+	{
+		const prefix string = ",\"timestamp\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Timestamp))
+	}
+	{
+		const prefix string = ",\"revision\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Revision))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Candle) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels19(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Candle) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels19(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Candle) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels19(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Candle) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels19(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels20(in *jlexer.Lexer, out *BuildInfo) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "version":
+			out.Version = string(in.String())
+		case "revision":
+			out.Revision = string(in.String())
+		case "build_time":
+			out.BuildTime = string(in.String())
+		case "go_version":
+			out.GoVersion = string(in.String())
+		case "modified":
+			out.Modified = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels20(out *jwriter.Writer, in BuildInfo) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"version\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Version))
+	}
+	if in.Revision != "" {
+		const prefix string = ",\"revision\":"
+		out.RawString(prefix)
+		out.String(string(in.Revision))
+	}
+	if in.BuildTime != "" {
+		const prefix string = ",\"build_time\":"
+		out.RawString(prefix)
+		out.String(string(in.BuildTime))
+	}
+	{
+		const prefix string = ",\"go_version\":"
+		out.RawString(prefix)
+		out.String(string(in.GoVersion))
+	}
+	if in.Modified {
+		const prefix string = ",\"modified\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Modified))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v BuildInfo) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels20(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v BuildInfo) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComInfinityCounter2VhTraderInternalModels20(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *BuildInfo) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels20(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *BuildInfo) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComInfinityCounter2VhTraderInternalModels20(l, v)
 }
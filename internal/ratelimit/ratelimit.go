@@ -0,0 +1,121 @@
+// Package ratelimit implements token bucket rate limits per client.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets that refilled are forgotten.
+const sweepInterval = time.Minute
+
+// Limiter holds a token bucket for each client, keyed by any string
+// identifying it. Buckets refill at the rate up to the burst, and
+// clients not seen yet start with a full bucket.
+type Limiter struct {
+	mtx   sync.Mutex
+	rate  float64
+	burst float64
+	// buckets is keyed by client.
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewLimiter creates a limiter refilling rate tokens a second up to
+// the burst, a rate of 0 disabling the limit.
+func NewLimiter(rate float64, burst int) *Limiter {
+	return &Limiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// SetLimit changes the rate and burst, keeping the tokens
+// of the buckets up to the new burst.
+func (l *Limiter) SetLimit(rate float64, burst int) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.rate = rate
+	l.burst = float64(burst)
+	for _, b := range l.buckets {
+		b.tokens = math.Min(b.tokens, l.burst)
+	}
+}
+
+// Enabled reports whether the limiter limits anything.
+func (l *Limiter) Enabled() bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	return l.rate > 0
+}
+
+// Burst returns the most tokens that can be taken at once.
+func (l *Limiter) Burst() int {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	return int(l.burst)
+}
+
+// Take takes n tokens from the bucket of the client, returning true if
+// there were enough, and otherwise how long until there will be. Taking
+// more than the burst always fails, so callers should check it first.
+func (l *Limiter) Take(client string, n int, now time.Time) (bool, time.Duration) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.rate <= 0 {
+		return true, 0
+	}
+	l.sweep(now)
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: l.burst, updated: now}
+		l.buckets[client] = b
+	}
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = math.Min(l.burst, b.tokens+elapsed.Seconds()*l.rate)
+		b.updated = now
+	}
+
+	if b.tokens < float64(n) {
+		missing := float64(n) - b.tokens
+		return false, time.Duration(math.Ceil(missing / l.rate * float64(time.Second)))
+	}
+	b.tokens -= float64(n)
+	return true, 0
+}
+
+// sweep forgets the buckets that have refilled since they were last
+// used, as those are the same as new buckets, bounding the memory
+// used to the clients seen recently.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for client, b := range l.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// Len returns the number of clients with buckets.
+func (l *Limiter) Len() int {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	return len(l.buckets)
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_Take(t *testing.T) {
+	l := NewLimiter(10, 20)
+	now := time.UnixMilli(1_700_000_000_000)
+
+	ok, _ := l.Take("a", 15, now)
+	require.True(t, ok, "Expected new clients to start with a full bucket")
+
+	ok, wait := l.Take("a", 10, now)
+	require.False(t, ok, "Expected an empty bucket to fail")
+	require.Equal(t, 500*time.Millisecond, wait, "Expected the time to refill the missing tokens")
+
+	ok, _ = l.Take("b", 20, now)
+	require.True(t, ok, "Expected buckets per client")
+
+	ok, _ = l.Take("a", 10, now.Add(500*time.Millisecond))
+	require.True(t, ok, "Expected the bucket to refill")
+
+	ok, _ = l.Take("a", 20, now.Add(time.Hour))
+	require.True(t, ok, "Expected the bucket to refill up to the burst")
+	ok, _ = l.Take("a", 1, now.Add(time.Hour))
+	require.False(t, ok, "Expected the bucket not to refill past the burst")
+}
+
+func TestLimiter_Disabled(t *testing.T) {
+	l := NewLimiter(0, 0)
+	require.False(t, l.Enabled())
+
+	ok, _ := l.Take("a", 1_000_000, time.Now())
+	require.True(t, ok, "Expected a zero rate not to limit")
+	require.Equal(t, 0, l.Len(), "Expected no buckets while disabled")
+}
+
+func TestLimiter_SetLimit(t *testing.T) {
+	l := NewLimiter(1, 10)
+	now := time.UnixMilli(1_700_000_000_000)
+
+	l.SetLimit(1, 5)
+	ok, _ := l.Take("a", 5, now)
+	require.True(t, ok)
+	ok, _ = l.Take("a", 1, now)
+	require.False(t, ok, "Expected buckets to be capped to the new burst")
+
+	l.SetLimit(100, 5)
+	ok, _ = l.Take("a", 5, now.Add(50*time.Millisecond))
+	require.True(t, ok, "Expected buckets to refill at the new rate")
+}
+
+func TestLimiter_Sweep(t *testing.T) {
+	l := NewLimiter(1, 100)
+	now := time.UnixMilli(1_700_000_000_000)
+	later := now.Add(sweepInterval)
+
+	l.Take("a", 100, now)
+	l.Take("b", 1, now)
+	require.Equal(t, 2, l.Len())
+
+	l.Take("c", 1, later)
+	require.Equal(t, 2, l.Len(), "Expected only the refilled bucket to be forgotten")
+
+	ok, _ := l.Take("b", 100, later)
+	require.True(t, ok, "Expected a forgotten bucket to start full")
+	ok, _ = l.Take("a", 100, later)
+	require.False(t, ok, "Expected a kept bucket to still be refilling")
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/infinityCounter2/vh-trader/internal/auth"
+)
+
+// apiKeyHeader carries the API key when not sent as a bearer token.
+const apiKeyHeader = "X-API-Key"
+
+// routeRoles is keyed by route pattern and contains the role required
+// for the route. Routes not listed require the admin role, unless they
+// are publicRoutes, so new routes aren't exposed by mistake.
+var routeRoles = map[string]auth.Role{
+	"/ingest":         auth.RoleIngest,
+	"/trades/cancel":  auth.RoleIngest,
+	"/trades/correct": auth.RoleIngest,
+
+	"/trades":      auth.RoleRead,
+	"/candles":     auth.RoleRead,
+	"/indicators":  auth.RoleRead,
+	"/ticker":      auth.RoleRead,
+	"/tickers":     auth.RoleRead,
+	"/late_trades": auth.RoleRead,
+	"/corrections": auth.RoleRead,
+	"/symbols":     auth.RoleRead,
+	"/metrics":     auth.RoleRead,
+	"/version":     auth.RoleRead,
+}
+
+// publicRoutes are served without an API key, so that
+// orchestrators can probe the server.
+var publicRoutes = []string{"/healthz", "/readyz"}
+
+// authorize checks the API key of the request grants the role of the
+// route, responding with an error and returning false if it doesn't.
+// Every request is authorized while no keyring is configured.
+//
+// The ingest routes also require a verified client certificate
+// with the ingest ClientCerts policy.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request, route string) (auth.Key, bool) {
+	if s.p.TLS != nil && s.p.TLS.ClientCerts == auth.ClientCertsIngest && routeRoles[route] == auth.RoleIngest &&
+		(r.TLS == nil || len(r.TLS.VerifiedChains) == 0) {
+		s.unauthorized(w, r, route, http.StatusUnauthorized, "Unauthorized, requires a client certificate")
+		return auth.Key{}, false
+	}
+
+	keyring := s.keyring.Load()
+	if keyring == nil || route == unmatchedRoute || slices.Contains(publicRoutes, route) {
+		return auth.Key{}, true
+	}
+
+	key, ok := keyring.Authenticate(requestAPIKey(r))
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="vh-trader"`)
+		s.unauthorized(w, r, route, http.StatusUnauthorized, "Unauthorized")
+		return auth.Key{}, false
+	}
+
+	role, ok := routeRoles[route]
+	if !ok {
+		role = auth.RoleAdmin
+	}
+	if !key.Has(role) {
+		s.unauthorized(w, r, route, http.StatusForbidden, fmt.Sprintf("Forbidden, requires the %s role", role))
+		return auth.Key{}, false
+	}
+	return key, true
+}
+
+// unauthorized responds to a request failing auth with the error, unless
+// its IP is over the rate limit of the route, so that keys can't be
+// guessed as fast as they can be checked.
+func (s *Server) unauthorized(w http.ResponseWriter, r *http.Request, route string, code int, msg string) {
+	limiter, name := s.readLimiter, "read"
+	if routeRoles[route] == auth.RoleIngest {
+		limiter, name = s.ingestLimiter, "ingest"
+	}
+	if s.takeRateLimit(w, r, limiter, name, 1) {
+		http.Error(w, msg, code)
+	}
+}
+
+// requestAPIKey returns the API key sent as a bearer
+// token or in the X-API-Key header.
+func requestAPIKey(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return r.Header.Get(apiKeyHeader)
+}
+
+// diffKeyrings describes the keys added, removed or changed,
+// and whether auth was enabled or disabled.
+func diffKeyrings(before, after *auth.Keyring) []string {
+	switch {
+	case before == nil && after == nil:
+		return nil
+	case before == nil:
+		return []string{"auth: enabled"}
+	case after == nil:
+		return []string{"auth: disabled"}
+	}
+
+	prev := make(map[string]auth.Key)
+	for _, key := range before.Keys() {
+		prev[key.Name] = key
+	}
+
+	var changes []string
+	for _, key := range after.Keys() {
+		old, ok := prev[key.Name]
+		delete(prev, key.Name)
+		switch {
+		case !ok:
+			changes = append(changes, "auth.keys: added "+key.Name)
+		case old.Hash != key.Hash || !slices.Equal(old.Roles, key.Roles):
+			changes = append(changes, "auth.keys: changed "+key.Name)
+		}
+	}
+	for _, key := range before.Keys() {
+		if _, removed := prev[key.Name]; removed {
+			changes = append(changes, "auth.keys: removed "+key.Name)
+		}
+	}
+	return changes
+}
+
+// signedRequest is the key and nonce of a verified signature.
+type signedRequest struct {
+	id, nonce string
+}
+
+// verifySignature checks the signature of the body when signing
+// is configured, responding with an error and returning false if
+// it is missing, invalid or has been used already.
+//
+// The nonce is only recorded by useNonce, once the request is
+// within its limits, so that a rejected request can be retried.
+func (s *Server) verifySignature(w http.ResponseWriter, r *http.Request, body []byte) (*signedRequest, bool) {
+	verifier := s.verifier.Load()
+	if verifier == nil {
+		return nil, true
+	}
+
+	id, nonce, err := verifier.Verify(r, body, time.Now())
+	if err == nil && s.nonces.Used(id, nonce, time.Now()) {
+		err = auth.ErrSignatureReplayed
+	}
+	if err != nil {
+		s.rejectSignature(w, r, err)
+		return nil, false
+	}
+	return &signedRequest{id: id, nonce: nonce}, true
+}
+
+// useNonce records the nonce of the signed request as used, responding
+// with an error and returning false if it was used since being verified.
+func (s *Server) useNonce(w http.ResponseWriter, r *http.Request, signed *signedRequest) bool {
+	verifier := s.verifier.Load()
+	if signed == nil || verifier == nil {
+		return true
+	}
+
+	// Nonces are kept for twice the max skew, covering
+	// every timestamp that could still be verified.
+	if !s.nonces.Use(signed.id, signed.nonce, time.Now(), 2*verifier.MaxSkew()) {
+		s.rejectSignature(w, r, auth.ErrSignatureReplayed)
+		return false
+	}
+	return true
+}
+
+func (s *Server) rejectSignature(w http.ResponseWriter, r *http.Request, err error) {
+	s.requestLogger(r).Warn("Rejected signature", slog.String("key", r.Header.Get(auth.SignatureKeyHeader)),
+		slog.Any("error", err))
+	http.Error(w, fmt.Sprintf("Unauthorized, %s", err), http.StatusUnauthorized)
+}
+
+// diffVerifiers describes the signing secrets added, removed or
+// changed, and whether signing was enabled or disabled.
+func diffVerifiers(before, after *auth.Verifier) []string {
+	switch {
+	case before == nil && after == nil:
+		return nil
+	case before == nil:
+		return []string{"auth.signing: enabled"}
+	case after == nil:
+		return []string{"auth.signing: disabled"}
+	}
+
+	var changes []string
+	if before.MaxSkew() != after.MaxSkew() {
+		changes = append(changes, fmt.Sprintf("auth.signing.max_skew: %s -> %s", before.MaxSkew(), after.MaxSkew()))
+	}
+	for _, id := range after.IDs() {
+		switch {
+		case !slices.Contains(before.IDs(), id):
+			changes = append(changes, "auth.signing.secrets: added "+id)
+		case !before.Equal(after, id):
+			changes = append(changes, "auth.signing.secrets: changed "+id)
+		}
+	}
+	for _, id := range before.IDs() {
+		if !slices.Contains(after.IDs(), id) {
+			changes = append(changes, "auth.signing.secrets: removed "+id)
+		}
+	}
+	return changes
+}
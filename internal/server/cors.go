@@ -0,0 +1,99 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CORSParams struct {
+	// AllowedOrigins are the origins browsers may call the server
+	// from, like https://charts.example.com, or "*" for any origin.
+	// CORS is disabled when empty.
+	AllowedOrigins []string
+	// AllowedMethods and AllowedHeaders are those cross-origin
+	// requests may use, ExposedHeaders are the response headers
+	// scripts can read.
+	AllowedMethods []string
+	AllowedHeaders []string
+	ExposedHeaders []string
+	// MaxAge is how long browsers may cache preflight responses.
+	MaxAge time.Duration
+}
+
+// cors sets the CORS headers of requests from allowed origins, returning
+// true if the request was a preflight which has been responded to.
+//
+// Preflights are responded to before authorizing, browsers
+// never send credentials with them, and are responded to
+// without CORS headers if the origin, method or headers
+// aren't allowed, which fails them.
+func (s *Server) cors(w http.ResponseWriter, r *http.Request) bool {
+	p := s.corsParams.Load()
+	if len(p.AllowedOrigins) == 0 {
+		return false
+	}
+
+	// Responses differ by origin, so caches must key them by it.
+	w.Header().Add("Vary", "Origin")
+	origin := r.Header.Get("Origin")
+	preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+	if preflight {
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+	}
+	if origin == "" {
+		return false
+	}
+
+	allowed := slices.Contains(p.AllowedOrigins, "*") || slices.Contains(p.AllowedOrigins, origin)
+	if !preflight {
+		if allowed {
+			p.setAllowOrigin(w, origin)
+			if len(p.ExposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(p.ExposedHeaders, ", "))
+			}
+		}
+		return false
+	}
+
+	if allowed && slices.Contains(p.AllowedMethods, r.Header.Get("Access-Control-Request-Method")) &&
+		p.headersAllowed(r.Header.Get("Access-Control-Request-Headers")) {
+		p.setAllowOrigin(w, origin)
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(p.AllowedMethods, ", "))
+		if len(p.AllowedHeaders) > 0 {
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(p.AllowedHeaders, ", "))
+		}
+		if p.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(p.MaxAge.Seconds())))
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
+
+func (p *CORSParams) setAllowOrigin(w http.ResponseWriter, origin string) {
+	if slices.Contains(p.AllowedOrigins, "*") {
+		origin = "*"
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+}
+
+// headersAllowed reports whether every header of the comma
+// separated list requested by a preflight is allowed.
+func (p *CORSParams) headersAllowed(requested string) bool {
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header == "" {
+			continue
+		}
+		if !slices.ContainsFunc(p.AllowedHeaders, func(allowed string) bool {
+			return strings.EqualFold(allowed, header)
+		}) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"sync"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+)
+
+// BuildTime is the time the binary was built, set at link time with
+// -ldflags "-X github.com/infinityCounter2/vh-trader/internal/server.BuildTime=...".
+var BuildTime string
+
+// buildInfo is read once as it can't change while running.
+var buildInfo = sync.OnceValue(readBuildInfo)
+
+func readBuildInfo() models.BuildInfo {
+	info := models.BuildInfo{
+		Version:   "(devel)",
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if bi.Main.Version != "" {
+		info.Version = bi.Main.Version
+	}
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.time":
+			if info.BuildTime == "" {
+				info.BuildTime = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
+
+// healthzHandler is a handler for the /healthz endpoint, it
+// responds OK for as long as the server is able to serve requests.
+func (s *Server) healthzHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Write([]byte("OK"))
+}
+
+// readyzHandler is a handler for the /readyz endpoint, it responds
+// OK unless the server is draining. Rebuilds keep serving the candles
+// they replace, so they don't fail readiness.
+func (s *Server) readyzHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.draining.Load() {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("OK"))
+}
+
+// versionHandler is a handler for the /version endpoint
+// serving the build info of the binary.
+func (s *Server) versionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.writeJSON(w, r, buildInfo())
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net/http"
+	"runtime"
+	"testing"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthz(t *testing.T) {
+	s, h := newTestServer(Params{APIKeys: testKeyring(t)})
+
+	w := serve(h, http.MethodGet, "/healthz", "", "")
+	require.Equal(t, http.StatusOK, w.Code, "Expected health checks without a key")
+	require.Equal(t, "OK", w.Body.String())
+
+	w = serve(h, http.MethodPost, "/healthz", "", "")
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code, "Expected only GET")
+
+	s.draining.Store(true)
+	w = serve(h, http.MethodGet, "/healthz", "", "")
+	require.Equal(t, http.StatusOK, w.Code, "Expected the server to stay healthy while draining")
+}
+
+func TestReadyz(t *testing.T) {
+	s, h := newTestServer(Params{APIKeys: testKeyring(t)})
+
+	w := serve(h, http.MethodGet, "/readyz", "", "")
+	require.Equal(t, http.StatusOK, w.Code, "Expected readiness checks without a key")
+	require.Equal(t, "OK", w.Body.String())
+
+	w = serve(h, http.MethodPost, "/readyz", "", "")
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code, "Expected only GET")
+
+	// Rebuilds keep serving the candles they replace.
+	s.rebuildMtx.Lock()
+	w = serve(h, http.MethodGet, "/readyz", "", "")
+	s.rebuildMtx.Unlock()
+	require.Equal(t, http.StatusOK, w.Code, "Expected the server to stay ready while rebuilding")
+
+	s.draining.Store(true)
+	w = serve(h, http.MethodGet, "/readyz", "", "")
+	require.Equal(t, http.StatusServiceUnavailable, w.Code, "Expected the server not to be ready while draining")
+	require.Contains(t, w.Body.String(), "shutting down")
+}
+
+func TestVersion(t *testing.T) {
+	_, h := newTestServer(Params{})
+
+	w := serve(h, http.MethodGet, "/version", "", "")
+	var info models.BuildInfo
+	decode(t, w, &info)
+	require.Equal(t, runtime.Version(), info.GoVersion)
+	require.NotEmpty(t, info.Version, "Expected a version")
+
+	defer func(buildTime string) { BuildTime = buildTime }(BuildTime)
+	BuildTime = "2026-10-18T12:00:00Z"
+	require.Equal(t, BuildTime, readBuildInfo().BuildTime, "Expected the build time set at link time")
+}
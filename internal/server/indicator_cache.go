@@ -0,0 +1,63 @@
+package server
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/infinityCounter2/vh-trader/internal/logic"
+)
+
+// maxIndicatorTrackers is how many indicator trackers are kept, the least
+// recently used is dropped to make room and recomputed if requested again.
+const maxIndicatorTrackers = 1024
+
+// indicatorCache holds the indicator trackers of the series requested.
+type indicatorCache struct {
+	mtx sync.Mutex
+	max int
+	// order holds the entries, the most recently used first.
+	order *list.List
+	// entries is keyed "symbol_interval_name_params".
+	entries map[string]*list.Element
+}
+
+type indicatorEntry struct {
+	key     string
+	tracker *logic.IndicatorTracker
+}
+
+func newIndicatorCache(max int) *indicatorCache {
+	return &indicatorCache{
+		max:     max,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// get returns the tracker of the key, adding the given tracker
+// if there is none.
+func (c *indicatorCache) get(key string, tracker *logic.IndicatorTracker) *logic.IndicatorTracker {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		return elem.Value.(*indicatorEntry).tracker
+	}
+
+	if c.order.Len() >= c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*indicatorEntry).key)
+	}
+	c.entries[key] = c.order.PushFront(&indicatorEntry{key: key, tracker: tracker})
+	return tracker
+}
+
+// len returns the number of trackers kept.
+func (c *indicatorCache) len() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	return c.order.Len()
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/infinityCounter2/vh-trader/internal/logic"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndicatorCache(t *testing.T) {
+	cache := newIndicatorCache(2)
+	tracker := func() *logic.IndicatorTracker {
+		tracker, err := logic.NewIndicatorTracker("sma", nil)
+		require.NoError(t, err, "Failed to create tracker")
+		return tracker
+	}
+
+	a, b := tracker(), tracker()
+	require.Same(t, a, cache.get("a", a))
+	require.Same(t, b, cache.get("b", b))
+	require.Same(t, a, cache.get("a", tracker()), "Expected the tracker of the key to be reused")
+
+	c := tracker()
+	require.Same(t, c, cache.get("c", c))
+	require.Equal(t, 2, cache.len(), "Expected the cache to stay bounded")
+	require.Same(t, a, cache.get("a", tracker()), "Expected the recently used tracker to be kept")
+	require.NotSame(t, b, cache.get("b", tracker()), "Expected the least recently used tracker to be dropped")
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/infinityCounter2/vh-trader/internal/auth"
+	"github.com/infinityCounter2/vh-trader/internal/ratelimit"
+)
+
+// Limits bound the requests of clients, 0 being no limit for each.
+type Limits struct {
+	// MaxBodyBytes limits the size of request bodies and
+	// MaxTradesPerBatch the number of trades of an ingest.
+	MaxBodyBytes      int64
+	MaxTradesPerBatch int
+
+	// IngestRate is the trades a second, and ReadRate the read requests
+	// a second, each client can send with bursts of up to IngestBurst and
+	// ReadBurst. Clients are told apart by API key, or IP without one.
+	// The bursts default to the rate, and the ingest burst to at least
+	// MaxTradesPerBatch so that full batches can be ingested.
+	IngestRate  int
+	IngestBurst int
+	ReadRate    int
+	ReadBurst   int
+}
+
+func (l Limits) ingestBurst() int {
+	if l.IngestBurst > 0 {
+		return l.IngestBurst
+	}
+	return max(l.IngestRate, l.MaxTradesPerBatch)
+}
+
+func (l Limits) readBurst() int {
+	if l.ReadBurst > 0 {
+		return l.ReadBurst
+	}
+	return l.ReadRate
+}
+
+// setLimits applies the limits going forward, keeping the
+// tokens clients have left under the rate limits.
+func (s *Server) setLimits(l Limits) {
+	s.limits.Store(&l)
+	s.ingestLimiter.SetLimit(float64(l.IngestRate), l.ingestBurst())
+	s.readLimiter.SetLimit(float64(l.ReadRate), l.readBurst())
+}
+
+type clientKey struct{}
+
+// requestClient returns who the rate limits of the
+// request apply to, its API key or else its IP.
+func requestClient(r *http.Request) string {
+	if client, ok := r.Context().Value(clientKey{}).(string); ok {
+		return client
+	}
+	return rateLimitClient(r, auth.Key{})
+}
+
+func rateLimitClient(r *http.Request, key auth.Key) string {
+	if key.Name != "" {
+		return "key:" + key.Name
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// takeRateLimit takes n tokens for the client of the request from the
+// limiter, responding with 429 and returning false if it has run out.
+func (s *Server) takeRateLimit(w http.ResponseWriter, r *http.Request, limiter *ratelimit.Limiter, name string, n int) bool {
+	ok, wait := limiter.Take(requestClient(r), n, time.Now())
+	if ok {
+		return true
+	}
+
+	s.metrics.rateLimited.Inc(name)
+	// Retry-After is in whole seconds, rounded up so
+	// that retrying then succeeds.
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	http.Error(w, fmt.Sprintf("Too Many Requests, %s rate limit exceeded", name), http.StatusTooManyRequests)
+	s.requestLogger(r).Warn("Rate limited", slog.String("limit", name), slog.Duration("retry_after", wait))
+	return false
+}
+
+// takeIngestLimit checks the batch of n trades is within the
+// limits of ingests, responding with an error if it isn't.
+func (s *Server) takeIngestLimit(w http.ResponseWriter, r *http.Request, n int) bool {
+	limits := s.limits.Load()
+	if limits.MaxTradesPerBatch > 0 && n > limits.MaxTradesPerBatch {
+		http.Error(w,
+			fmt.Sprintf("batch of %d trades exceeds %d trades", n, limits.MaxTradesPerBatch),
+			http.StatusRequestEntityTooLarge,
+		)
+		return false
+	}
+	// Batches larger than the burst could never be taken.
+	if s.ingestLimiter.Enabled() && n > s.ingestLimiter.Burst() {
+		http.Error(w,
+			fmt.Sprintf("batch of %d trades exceeds the ingest burst of %d trades", n, s.ingestLimiter.Burst()),
+			http.StatusRequestEntityTooLarge,
+		)
+		return false
+	}
+	return s.takeRateLimit(w, r, s.ingestLimiter, "ingest", n)
+}
+
+// diffRequestLimits describes the limits changed.
+func diffRequestLimits(before, after Limits) []string {
+	var changes []string
+	for _, l := range []struct {
+		name          string
+		before, after int64
+	}{
+		{"max_body_bytes", before.MaxBodyBytes, after.MaxBodyBytes},
+		{"max_trades_per_batch", int64(before.MaxTradesPerBatch), int64(after.MaxTradesPerBatch)},
+		{"ingest_rate", int64(before.IngestRate), int64(after.IngestRate)},
+		{"ingest_burst", int64(before.IngestBurst), int64(after.IngestBurst)},
+		{"read_rate", int64(before.ReadRate), int64(after.ReadRate)},
+		{"read_burst", int64(before.ReadBurst), int64(after.ReadBurst)},
+	} {
+		if l.before != l.after {
+			changes = append(changes, fmt.Sprintf("limits.%s: %d -> %d", l.name, l.before, l.after))
+		}
+	}
+	return changes
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// requestIDHeader carries the ID of a request, it is propagated when
+// set by the client or a proxy and generated otherwise.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen bounds the length of propagated
+// request IDs as they end up in every log line.
+const maxRequestIDLen = 128
+
+type loggerKey struct{}
+
+// requestLogger returns the logger of the request,
+// which tags every record with the request ID.
+func (s *Server) requestLogger(r *http.Request) *slog.Logger {
+	if logger, ok := r.Context().Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return s.logger
+}
+
+// requestID returns the ID sent with the request if it is usable,
+// otherwise a newly generated one.
+func requestID(r *http.Request) string {
+	if id := r.Header.Get(requestIDHeader); id != "" && validRequestID(id) {
+		return id
+	}
+
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// validRequestID only allows printable ASCII so that
+// a client can't inject anything into the logs.
+func validRequestID(id string) bool {
+	if len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// responseRecorder records the status code and
+// the number of bytes written of a response.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// logRequest logs the outcome of a request once it was served.
+func logRequest(logger *slog.Logger, r *http.Request, route string, rec *responseRecorder, elapsed time.Duration) {
+	status := rec.status
+	if status == 0 {
+		// Nothing was written, net/http responds with a 200.
+		status = http.StatusOK
+	}
+
+	level := slog.LevelInfo
+	if status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	logger.LogAttrs(context.Background(), level, "request",
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.String("route", route),
+		slog.Int("status", status),
+		slog.Int64("bytes", rec.bytes),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+		slog.String("remote_addr", r.RemoteAddr),
+	)
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/infinityCounter2/vh-trader/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+// loggedServer returns the handler of a server logging as JSON to logs.
+func loggedServer(t *testing.T, p Params, logs *bytes.Buffer) http.Handler {
+	t.Helper()
+	p.Logger = slog.New(slog.NewJSONHandler(logs, nil))
+	return NewServer(p).handler()
+}
+
+// logRecords returns the records logged with the message.
+func logRecords(t *testing.T, logs []byte, msg string) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(logs))
+	for scanner.Scan() {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record), "Failed to parse log line")
+		if record["msg"] == msg {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+func TestMiddleware_RequestID(t *testing.T) {
+	var logs bytes.Buffer
+	h := loggedServer(t, Params{}, &logs)
+	body := tradesBody(t, models.Trade{TradeID: "1", Symbol: "BTC_USD", Price: 100, Size: 1, Timestamp: 1672531200000})
+
+	send := func(id string) string {
+		logs.Reset()
+		r := httptest.NewRequest(http.MethodPost, "/ingest", strings.NewReader(body))
+		if id != "" {
+			r.Header.Set(requestIDHeader, id)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code, "Unexpected status: %s", w.Body.String())
+
+		// Both the lines of the handler and of the request are tagged.
+		ingests, requests := logRecords(t, logs.Bytes(), "ingest"), logRecords(t, logs.Bytes(), "request")
+		require.Len(t, ingests, 1, "Expected the ingest to be logged")
+		require.Len(t, requests, 1, "Expected the request to be logged")
+		require.Equal(t, requests[0]["request_id"], ingests[0]["request_id"], "Expected the same ID on every line")
+		require.Equal(t, w.Header().Get(requestIDHeader), requests[0]["request_id"], "Expected the ID to be echoed")
+		return w.Header().Get(requestIDHeader)
+	}
+
+	id := send("trace-1234:abc")
+	require.Equal(t, "trace-1234:abc", id, "Expected the ID sent to be propagated")
+
+	generated := send("")
+	require.Len(t, generated, 16, "Expected an ID to be generated")
+	other := send("")
+	require.NotEqual(t, generated, other, "Expected every request to get its own ID")
+
+	for _, invalid := range []string{
+		strings.Repeat("a", maxRequestIDLen+1),
+		"id with spaces",
+		"id\x00null",
+		"é",
+	} {
+		id := send(invalid)
+		require.NotEqual(t, invalid, id, "Expected %q to be replaced", invalid)
+		require.Len(t, id, 16, "Expected an ID to be generated for %q", invalid)
+	}
+
+	id = send(strings.Repeat("a", maxRequestIDLen))
+	require.Equal(t, strings.Repeat("a", maxRequestIDLen), id, "Expected IDs up to the max length to be propagated")
+}
+
+func TestMiddleware_LogsResponse(t *testing.T) {
+	var logs bytes.Buffer
+	h := loggedServer(t, Params{APIKeys: testKeyring(t)}, &logs)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		key    string
+		route  string
+		status int
+	}{
+		{"served", http.MethodGet, "/healthz", "", "/healthz", http.StatusOK},
+		{"error", http.MethodGet, "/trades", readKey, "/trades", http.StatusBadRequest},
+		{"unauthorized", http.MethodGet, "/trades?symbol=BTC_USD", "", "/trades", http.StatusUnauthorized},
+		{"unmatched", http.MethodGet, "/nope", "", unmatchedRoute, http.StatusNotFound},
+		{"method", http.MethodPost, "/healthz", "", "/healthz", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		logs.Reset()
+		w := serve(h, tt.method, tt.target, tt.key, "")
+		require.Equal(t, tt.status, w.Code, "Unexpected status of %s", tt.name)
+
+		records := logRecords(t, logs.Bytes(), "request")
+		require.Len(t, records, 1, "Expected one request line for %s", tt.name)
+		record := records[0]
+		require.Equal(t, float64(tt.status), record["status"], "Expected the status written for %s", tt.name)
+		require.Equal(t, float64(w.Body.Len()), record["bytes"], "Expected the bytes written for %s", tt.name)
+		require.Equal(t, tt.route, record["route"], "Unexpected route of %s", tt.name)
+		require.Equal(t, tt.method, record["method"])
+		require.Equal(t, "INFO", record["level"], "Expected client errors to be logged as info for %s", tt.name)
+	}
+}
+
+func TestResponseRecorder(t *testing.T) {
+	w := httptest.NewRecorder()
+	rec := &responseRecorder{ResponseWriter: w}
+	require.Equal(t, 0, rec.status, "Expected no status before writing")
+
+	n, err := rec.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.Equal(t, 5, n)
+	rec.WriteHeader(http.StatusInternalServerError)
+	rec.Write([]byte(" world"))
+	require.Equal(t, http.StatusOK, rec.status, "Expected the implicit status of the first write to be kept")
+	require.Equal(t, int64(11), rec.bytes, "Expected every byte written to be counted")
+
+	rec = &responseRecorder{ResponseWriter: httptest.NewRecorder()}
+	rec.WriteHeader(http.StatusAccepted)
+	rec.WriteHeader(http.StatusTeapot)
+	require.Equal(t, http.StatusAccepted, rec.status, "Expected the first status to be kept")
+
+	var logs bytes.Buffer
+	logRequest(slog.New(slog.NewJSONHandler(&logs, nil)), httptest.NewRequest(http.MethodGet, "/", nil), "/",
+		&responseRecorder{ResponseWriter: httptest.NewRecorder()}, 0)
+	records := logRecords(t, logs.Bytes(), "request")
+	require.Len(t, records, 1)
+	require.Equal(t, float64(http.StatusOK), records[0]["status"], "Expected a 200 when nothing was written")
+
+	logs.Reset()
+	logRequest(slog.New(slog.NewJSONHandler(&logs, nil)), httptest.NewRequest(http.MethodGet, "/", nil), "/",
+		&responseRecorder{ResponseWriter: httptest.NewRecorder(), status: http.StatusBadGateway}, 0)
+	records = logRecords(t, logs.Bytes(), "request")
+	require.Len(t, records, 1)
+	require.Equal(t, "ERROR", records[0]["level"], "Expected server errors to be logged as errors")
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"net/http"
+	"runtime"
+
+	"github.com/infinityCounter2/vh-trader/internal/metrics"
+)
+
+// unknownSymbol labels the trades rejected for a symbol that isn't
+// registered, as any symbol can be sent.
+const unknownSymbol = "unknown"
+
+// metricMethods are the request methods labeled as is,
+// any other is labeled "other" as any method can be sent.
+var metricMethods = map[string]struct{}{
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodPost:    {},
+	http.MethodPut:     {},
+	http.MethodPatch:   {},
+	http.MethodDelete:  {},
+	http.MethodOptions: {},
+}
+
+// serverMetrics are the metrics served on /metrics.
+type serverMetrics struct {
+	registry *metrics.Registry
+
+	// ingestedTrades is labeled by symbol and result, one of accepted,
+	// duplicate or rejected. Rejected trades of symbols that aren't
+	// registered are labeled unknownSymbol.
+	ingestedTrades *metrics.CounterVec
+	// lastIngest is the wall clock time(s) trades of the symbol were
+	// last accepted, alerting on its age catches feed stalls.
+	lastIngest *metrics.GaugeVec
+	// requestDuration is labeled by route and method, see metricMethod.
+	requestDuration *metrics.HistogramVec
+	// rateLimited is labeled by limit, either ingest or read.
+	rateLimited *metrics.CounterVec
+}
+
+// newServerMetrics registers the metrics of the server, the metrics of
+// state the server already keeps are collected from it when scraped.
+func newServerMetrics(s *Server) *serverMetrics {
+	r := metrics.NewRegistry()
+	m := &serverMetrics{
+		registry: r,
+		ingestedTrades: r.NewCounterVec("vh_ingest_trades_total",
+			"Trades received on /ingest by symbol and result.", "symbol", "result"),
+		lastIngest: r.NewGaugeVec("vh_ingest_last_accepted_timestamp_seconds",
+			"Wall clock time trades of the symbol were last accepted.", "symbol"),
+		requestDuration: r.NewHistogramVec("vh_http_request_duration_seconds",
+			"Latency of HTTP requests by route and method.", metrics.DefBuckets, "route", "method"),
+		rateLimited: r.NewCounterVec("vh_rate_limited_requests_total",
+			"Requests rejected by a rate limit, either ingest or read.", "limit"),
+	}
+
+	r.NewGaugeFunc("vh_known_trades", "Trades in the dedup set, including cancelled ones.", nil,
+		func(emit metrics.Emit) {
+			s.knwnMtx.Lock()
+			defer s.knwnMtx.Unlock()
+			emit(float64(len(s.knownTrades)))
+		})
+
+	r.NewGaugeFunc("vh_trade_store_trades", "Trades cached in the trade store by symbol.", []string{"symbol"},
+		func(emit metrics.Emit) {
+			for symbol, size := range s.tradeStore.Sizes() {
+				emit(float64(size), symbol)
+			}
+		})
+
+	r.NewGaugeFunc("vh_trade_history_trades", "Trades in the trade history by symbol.", []string{"symbol"},
+		func(emit metrics.Emit) {
+			for _, symbol := range s.tradeHistory.Symbols() {
+				stats, _ := s.tradeHistory.Stats(symbol)
+				emit(float64(stats.TradeCount), symbol)
+			}
+		})
+
+	r.NewGaugeFunc("vh_candle_builders", "Candle builders of every interval.", nil,
+		func(emit metrics.Emit) {
+			s.builderMtx.RLock()
+			defer s.builderMtx.RUnlock()
+			emit(float64(len(s.builders)))
+		})
+
+	r.NewGaugeFunc("vh_bar_builders", "Bar builders of every type.", nil,
+		func(emit metrics.Emit) {
+			s.barMtx.RLock()
+			defer s.barMtx.RUnlock()
+			emit(float64(len(s.bars)))
+		})
+
+	r.NewCounterFunc("vh_late_trades_total", "Late trades by symbol and outcome, one of late, dropped or corrected.",
+		[]string{"symbol", "outcome"},
+		func(emit metrics.Emit) {
+			s.builderMtx.RLock()
+			defer s.builderMtx.RUnlock()
+			for symbol, series := range s.series {
+				// Every interval reports the late trades of the finest one.
+				stats := series.Builder(s.intervals[0]).LateStats()
+				emit(float64(stats.Late), symbol, "late")
+				emit(float64(stats.Dropped), symbol, "dropped")
+				emit(float64(stats.Corrected), symbol, "corrected")
+			}
+		})
+
+	r.NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", nil,
+		func(emit metrics.Emit) {
+			emit(float64(runtime.NumGoroutine()))
+		})
+
+	// Read once per scrape for all the memory metrics.
+	var mem runtime.MemStats
+	r.NewGaugeFunc("go_memstats_heap_alloc_bytes", "Bytes of allocated heap objects.", nil,
+		func(emit metrics.Emit) {
+			runtime.ReadMemStats(&mem)
+			emit(float64(mem.HeapAlloc))
+		})
+	r.NewGaugeFunc("go_memstats_heap_inuse_bytes", "Bytes in in-use heap spans.", nil,
+		func(emit metrics.Emit) {
+			emit(float64(mem.HeapInuse))
+		})
+	r.NewGaugeFunc("go_memstats_sys_bytes", "Bytes of memory obtained from the OS.", nil,
+		func(emit metrics.Emit) {
+			emit(float64(mem.Sys))
+		})
+	r.NewCounterFunc("go_gc_cycles_total", "Completed GC cycles.", nil,
+		func(emit metrics.Emit) {
+			emit(float64(mem.NumGC))
+		})
+
+	return m
+}
+
+// metricMethod returns the method label of the request.
+func metricMethod(r *http.Request) string {
+	if _, ok := metricMethods[r.Method]; ok {
+		return r.Method
+	}
+	return "other"
+}
+
+// metricSymbol returns the symbol label of a rejected trade.
+func (s *Server) metricSymbol(symbol string) string {
+	if _, ok := s.symbols.Get(symbol); ok {
+		return symbol
+	}
+	return unknownSymbol
+}
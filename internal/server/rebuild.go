@@ -0,0 +1,222 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/infinityCounter2/vh-trader/internal/logic"
+	"github.com/infinityCounter2/vh-trader/internal/models"
+)
+
+// rebuildBatchSize is the number of trades replayed
+// between updates of the progress of a rebuild job.
+const rebuildBatchSize = 1000
+
+// adminRebuildHandler is a handler for the /admin/rebuild endpoint.
+//
+// POST starts rebuilding the candles of the required "symbol" from its
+// trade history, optionally only for an "interval" and the candles within
+// the "from" and "to" timestamps(ms). It responds with the job, which
+// GET serves by "id", or every job when no "id" is given.
+func (s *Server) adminRebuildHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.startRebuild(w, r)
+
+	case http.MethodGet:
+		s.rebuildJobsMtx.RLock()
+		defer s.rebuildJobsMtx.RUnlock()
+
+		if id := getParam(r, "id"); id != "" {
+			job, ok := s.rebuildJobs[id]
+			if !ok {
+				http.Error(w, fmt.Sprintf("unknown rebuild job %q", id), http.StatusNotFound)
+				return
+			}
+			s.writeJSON(w, r, *job)
+			return
+		}
+
+		jobs := make(models.RebuildJobList, 0, len(s.rebuildJobs))
+		for _, job := range s.rebuildJobs {
+			jobs = append(jobs, *job)
+		}
+		sort.Slice(jobs, func(i, j int) bool {
+			return jobs[i].StartedAt < jobs[j].StartedAt
+		})
+		s.writeJSON(w, r, jobs)
+
+	default:
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) startRebuild(w http.ResponseWriter, r *http.Request) {
+	symbol := s.getSymbolParam(r)
+	if symbol == "" {
+		http.Error(w, "symbol is required", http.StatusBadRequest)
+		return
+	}
+
+	series := s.symbolSeries(symbol)
+	if series == nil {
+		http.Error(w, fmt.Sprintf("no candles for symbol %q", symbol), http.StatusNotFound)
+		return
+	}
+
+	// The range is widened to whole candles of the
+	// interval, or of every interval when rebuilding all.
+	var intvl logic.BuilderInterval
+	align := slices.Max(s.intervals)
+	if intvlArg := getParam(r, "interval"); intvlArg != "" {
+		var ok bool
+		intvl, ok = s.parseBuilderInterval(intvlArg)
+		if !ok {
+			http.Error(w,
+				fmt.Sprintf("invalid interval value %q", intvlArg),
+				http.StatusBadRequest,
+			)
+			return
+		}
+		align = intvl
+	}
+
+	from, err := getInt64Param(r, "from")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := getInt64Param(r, "to")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if from < 0 || to < 0 || (to != 0 && to <= from) {
+		http.Error(w, "from must be before to", http.StatusBadRequest)
+		return
+	}
+
+	from = time.UnixMilli(from).Truncate(align).UnixMilli()
+	if to != 0 {
+		end := time.UnixMilli(to)
+		if aligned := end.Truncate(align); aligned.Before(end) {
+			end = aligned.Add(align)
+		}
+		to = end.UnixMilli()
+	}
+
+	s.rebuildJobsMtx.Lock()
+	s.rebuildJobSeq++
+	job := &models.RebuildJob{
+		ID:        fmt.Sprintf("rebuild-%d", s.rebuildJobSeq),
+		Symbol:    symbol,
+		From:      from,
+		To:        to,
+		StartedAt: time.Now().UnixMilli(),
+		Status:    models.RebuildStatusRunning,
+	}
+	if intvl != 0 {
+		job.Interval = formatBuilderInterval(intvl)
+	}
+	s.rebuildJobs[job.ID] = job
+	started := *job
+	s.rebuildJobsMtx.Unlock()
+
+	go s.runRebuild(job.ID, series, intvl)
+
+	// The status has to be written before writeJSON writes the body.
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	s.writeJSON(w, r, started)
+}
+
+// runRebuild replays the trade history of the job into a fresh candle
+// series and swaps its candles into the series of the symbol.
+func (s *Server) runRebuild(id string, series *logic.CandleSeries, intvl logic.BuilderInterval) {
+	s.rebuildJobsMtx.RLock()
+	job := *s.rebuildJobs[id]
+	s.rebuildJobsMtx.RUnlock()
+
+	query := logic.TradeQuery{Symbol: job.Symbol, From: job.From, To: job.To}
+	trades := s.tradeHistory.Query(query).Trades
+	s.updateRebuildJob(id, func(job *models.RebuildJob) {
+		job.TradesTotal = int64(len(trades))
+	})
+
+	rebuilt := s.newCandleSeries()
+	for start := 0; start < len(trades); start += rebuildBatchSize {
+		end := min(start+rebuildBatchSize, len(trades))
+		rebuilt.ProcessTrades(trades[start:end])
+		s.updateRebuildJob(id, func(job *models.RebuildJob) {
+			job.TradesProcessed = int64(end)
+		})
+	}
+
+	// Block trades from being applied while swapping, and replay again
+	// if the history changed while replaying so no trade is lost.
+	s.rebuildMtx.Lock()
+	if latest := s.tradeHistory.Query(query).Trades; !slices.Equal(latest, trades) {
+		rebuilt = s.newCandleSeries()
+		rebuilt.ProcessTrades(latest)
+		trades = latest
+	}
+
+	to := job.To
+	if to == 0 {
+		to = math.MaxInt64
+	}
+	series.Replace(rebuilt, intvl, job.From, to)
+	s.rebuildMtx.Unlock()
+
+	s.resetTicker(job.Symbol)
+
+	s.logger.Info("rebuild done",
+		slog.String("job_id", id),
+		slog.String("symbol", job.Symbol),
+		slog.Int("trades", len(trades)),
+	)
+
+	s.updateRebuildJob(id, func(job *models.RebuildJob) {
+		job.TradesTotal = int64(len(trades))
+		job.TradesProcessed = int64(len(trades))
+		job.Status = models.RebuildStatusDone
+		job.FinishedAt = time.Now().UnixMilli()
+	})
+}
+
+// recomputeCandles rebuilds the candles of every interval containing the
+// given timestamps(ms) from the trade history of the symbol, for trades the
+// series can no longer cancel or correct itself as their candle's trades
+// were pruned, or the late policy never applied them.
+//
+// The caller must hold rebuildMtx for reading.
+func (s *Server) recomputeCandles(series *logic.CandleSeries, symbol string, timestamps ...int64) {
+	// The candles of every interval are rebuilt over whole candles of the coarsest.
+	align := slices.Max(s.intervals)
+	done := make(map[int64]bool)
+	for _, ts := range timestamps {
+		from := time.UnixMilli(ts).Truncate(align).UnixMilli()
+		if done[from] {
+			continue
+		}
+		done[from] = true
+
+		to := from + align.Milliseconds()
+		rebuilt := s.newCandleSeries()
+		rebuilt.ProcessTrades(s.tradeHistory.Query(logic.TradeQuery{Symbol: symbol, From: from, To: to}).Trades)
+		series.Replace(rebuilt, 0, from, to)
+	}
+	s.resetTicker(symbol)
+}
+
+func (s *Server) updateRebuildJob(id string, update func(job *models.RebuildJob)) {
+	s.rebuildJobsMtx.Lock()
+	defer s.rebuildJobsMtx.Unlock()
+
+	update(s.rebuildJobs[id])
+}
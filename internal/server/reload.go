@@ -0,0 +1,200 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/infinityCounter2/vh-trader/internal/auth"
+	"github.com/infinityCounter2/vh-trader/internal/logic"
+	"github.com/infinityCounter2/vh-trader/internal/models"
+)
+
+// Reloadable are the settings that can change while running,
+// applied again by Server.Reload.
+type Reloadable struct {
+	// Symbols replaces the symbol registry, unless nil as
+	// when symbols are only managed through /admin/symbols.
+	// The edits made through /admin/symbols are discarded.
+	Symbols *logic.SymbolRegistry
+
+	CacheLimit        int
+	SymbolCacheLimits map[string]int
+
+	// APIKeys replaces the keys authorizing requests,
+	// nil disables authorization.
+	APIKeys *auth.Keyring
+	// Signing replaces the secrets batches ingested are
+	// signed with, nil stops requiring signatures.
+	Signing *auth.Verifier
+
+	// Limits replaces the limits of requests, keeping the
+	// tokens clients have left under the rate limits.
+	Limits Limits
+
+	// LogLevel is applied to Params.LogLevel, if set.
+	LogLevel slog.Level
+	// CORS replaces the CORS settings of the requests that follow.
+	CORS CORSParams
+}
+
+// Reload loads the Reloadable settings with Params.Reload and applies
+// them, logging and returning the changes. In memory state like candles
+// is kept, settings are only applied going forward.
+func (s *Server) Reload() ([]string, error) {
+	if s.p.Reload == nil {
+		return nil, errors.New("reloading is not configured")
+	}
+
+	// Reloads apply the settings in full so
+	// two of them can't be interleaved.
+	s.reloadMtx.Lock()
+	defer s.reloadMtx.Unlock()
+
+	next, err := s.p.Reload()
+	if err != nil {
+		s.logger.Error("Failed to reload config", slog.Any("error", err))
+		return nil, err
+	}
+
+	changes := make([]string, 0)
+	if next.Symbols != nil {
+		before, after := s.symbols.Replace(next.Symbols)
+		if s.symbolsEdited.Swap(false) {
+			s.logger.Warn("Reloading symbols.file discarded the edits made through /admin/symbols")
+		}
+		changes = append(changes, diffSymbols(before, after)...)
+	}
+
+	if s.reloadable.CacheLimit != next.CacheLimit ||
+		!maps.Equal(s.reloadable.SymbolCacheLimits, next.SymbolCacheLimits) {
+		s.tradeStore.SetLimits(next.CacheLimit, next.SymbolCacheLimits)
+	}
+	if s.reloadable.CacheLimit != next.CacheLimit {
+		changes = append(changes, fmt.Sprintf("cache.limit: %d -> %d", s.reloadable.CacheLimit, next.CacheLimit))
+	}
+	changes = append(changes, diffLimits("cache.symbol_limits",
+		s.reloadable.SymbolCacheLimits, next.SymbolCacheLimits)...)
+
+	s.keyring.Store(next.APIKeys)
+	changes = append(changes, diffKeyrings(s.reloadable.APIKeys, next.APIKeys)...)
+
+	s.verifier.Store(next.Signing)
+	changes = append(changes, diffVerifiers(s.reloadable.Signing, next.Signing)...)
+
+	s.setLimits(next.Limits)
+	changes = append(changes, diffRequestLimits(s.reloadable.Limits, next.Limits)...)
+
+	if s.p.LogLevel != nil {
+		s.p.LogLevel.Set(next.LogLevel)
+		if s.reloadable.LogLevel != next.LogLevel {
+			changes = append(changes, fmt.Sprintf("log.level: %s -> %s", s.reloadable.LogLevel, next.LogLevel))
+		}
+	} else {
+		next.LogLevel = s.reloadable.LogLevel
+	}
+
+	s.corsParams.Store(&next.CORS)
+	changes = append(changes, diffCORS(s.reloadable.CORS, next.CORS)...)
+
+	s.reloadable = next
+	s.reloadable.Symbols = nil
+
+	s.logger.Info("Reloaded config", slog.Any("changes", changes))
+	return changes, nil
+}
+
+// diffSymbols describes the symbols added, removed or changed,
+// both lists being sorted by symbol.
+func diffSymbols(before, after []models.Symbol) []string {
+	var changes []string
+
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case j == len(after) || (i < len(before) && before[i].Symbol < after[j].Symbol):
+			changes = append(changes, "symbols: removed "+before[i].Symbol)
+			i++
+		case i == len(before) || after[j].Symbol < before[i].Symbol:
+			changes = append(changes, "symbols: added "+after[j].Symbol)
+			j++
+		default:
+			if before[i] != after[j] {
+				changes = append(changes, "symbols: changed "+after[j].Symbol)
+			}
+			i++
+			j++
+		}
+	}
+	return changes
+}
+
+// diffCORS describes the CORS settings changed.
+func diffCORS(before, after CORSParams) []string {
+	var changes []string
+	for _, l := range []struct {
+		name          string
+		before, after []string
+	}{
+		{"allowed_origins", before.AllowedOrigins, after.AllowedOrigins},
+		{"allowed_methods", before.AllowedMethods, after.AllowedMethods},
+		{"allowed_headers", before.AllowedHeaders, after.AllowedHeaders},
+		{"exposed_headers", before.ExposedHeaders, after.ExposedHeaders},
+	} {
+		if !slices.Equal(l.before, l.after) {
+			changes = append(changes, fmt.Sprintf("cors.%s: [%s] -> [%s]", l.name,
+				strings.Join(l.before, ", "), strings.Join(l.after, ", ")))
+		}
+	}
+	if before.MaxAge != after.MaxAge {
+		changes = append(changes, fmt.Sprintf("cors.max_age: %s -> %s", before.MaxAge, after.MaxAge))
+	}
+	return changes
+}
+
+// diffLimits describes the limits added, removed or changed, sorted by key.
+func diffLimits(name string, before, after map[string]int) []string {
+	keys := slices.Sorted(maps.Keys(before))
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	var changes []string
+	for _, k := range keys {
+		prev, hadPrev := before[k]
+		next, hasNext := after[k]
+		switch {
+		case !hasNext:
+			changes = append(changes, fmt.Sprintf("%s.%s: removed", name, k))
+		case !hadPrev:
+			changes = append(changes, fmt.Sprintf("%s.%s: added %d", name, k, next))
+		case prev != next:
+			changes = append(changes, fmt.Sprintf("%s.%s: %d -> %d", name, k, prev, next))
+		}
+	}
+	return changes
+}
+
+// adminReloadHandler is a handler for the /admin/reload endpoint,
+// a POST reloads the config like a SIGHUP and responds with the changes.
+func (s *Server) adminReloadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	changes, err := s.Reload()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to reload config: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSON(w, r, models.ConfigReload{Changes: changes})
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/infinityCounter2/vh-trader/internal/logic"
+	"github.com/infinityCounter2/vh-trader/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	symbols := func(names ...string) *logic.SymbolRegistry {
+		list := make([]models.Symbol, 0, len(names))
+		for _, name := range names {
+			list = append(list, models.Symbol{Symbol: name, Status: models.SymbolStatusTrading})
+		}
+		registry, err := logic.NewSymbolRegistry(list)
+		require.NoError(t, err, "Failed to create registry")
+		return registry
+	}
+
+	var logs bytes.Buffer
+	level := new(slog.LevelVar)
+	var reloadErr error
+	s := NewServer(Params{
+		CORS:       CORSParams{AllowedOrigins: []string{"https://a.example.com"}, AllowedMethods: []string{http.MethodGet}},
+		CacheLimit: 10,
+		Symbols:    symbols("BTC_USD"),
+		Limits:     Limits{MaxTradesPerBatch: 10},
+		Logger:     slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: level})),
+		LogLevel:   level,
+		Reload: func() (Reloadable, error) {
+			return Reloadable{
+				Symbols:           symbols("BTC_USD", "ETH_USD"),
+				CacheLimit:        20,
+				SymbolCacheLimits: map[string]int{"BTC_USD": 5},
+				Limits:            Limits{MaxTradesPerBatch: 1},
+				LogLevel:          slog.LevelDebug,
+				CORS: CORSParams{
+					AllowedOrigins: []string{"https://b.example.com"},
+					AllowedMethods: []string{http.MethodGet},
+					MaxAge:         time.Minute,
+				},
+			}, reloadErr
+		},
+	})
+	h := s.handler()
+
+	w := serve(h, http.MethodPut, "/admin/symbols", "", `{"symbol":"SOL_USD","status":"trading"}`)
+	require.Equal(t, http.StatusOK, w.Code, "Failed to add symbol: %s", w.Body.String())
+
+	w = serve(h, http.MethodPost, "/admin/reload", "", "")
+	var reload models.ConfigReload
+	decode(t, w, &reload)
+	require.Equal(t, []string{
+		"symbols: added ETH_USD",
+		"symbols: removed SOL_USD",
+		"cache.limit: 10 -> 20",
+		"cache.symbol_limits.BTC_USD: added 5",
+		"limits.max_trades_per_batch: 10 -> 1",
+		"log.level: INFO -> DEBUG",
+		"cors.allowed_origins: [https://a.example.com] -> [https://b.example.com]",
+		"cors.max_age: 0s -> 1m0s",
+	}, reload.Changes, "Unexpected changes")
+	require.Contains(t, logs.String(), "discarded the edits made through /admin/symbols",
+		"Expected the discarded symbol edits to be logged")
+
+	require.Equal(t, slog.LevelDebug, level.Level(), "Expected the log level to be applied")
+	w = serve(h, http.MethodPost, "/ingest", "", `[{"trade_id":"1"},{"trade_id":"2"}]`)
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code, "Expected the limits to be applied")
+
+	cors := func(origin string) string {
+		r := httptest.NewRequest(http.MethodGet, "/symbols", nil)
+		r.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Header().Get("Access-Control-Allow-Origin")
+	}
+	require.Empty(t, cors("https://a.example.com"), "Expected the previous origin to be disallowed")
+	require.Equal(t, "https://b.example.com", cors("https://b.example.com"), "Expected the new origin to be allowed")
+
+	// Reloading the same settings changes nothing and
+	// doesn't warn again without new symbol edits.
+	logs.Reset()
+	w = serve(h, http.MethodPost, "/admin/reload", "", "")
+	decode(t, w, &reload)
+	require.Empty(t, reload.Changes, "Expected no changes")
+	require.NotContains(t, logs.String(), "discarded", "Expected no warning without symbol edits")
+
+	reloadErr = errors.New("invalid config")
+	w = serve(h, http.MethodPost, "/admin/reload", "", "")
+	require.Equal(t, http.StatusInternalServerError, w.Code, "Expected failed reloads to fail")
+	require.Contains(t, w.Body.String(), "invalid config")
+}
+
+func TestReload_NotConfigured(t *testing.T) {
+	s, h := newTestServer(Params{})
+	_, err := s.Reload()
+	require.Error(t, err, "Expected reloads without Params.Reload to fail")
+
+	w := serve(h, http.MethodGet, "/admin/reload", "", "")
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code, "Expected only POST")
+}
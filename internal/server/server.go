@@ -5,50 +5,251 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/infinityCounter2/vh-trader/internal/auth"
 	"github.com/infinityCounter2/vh-trader/internal/logic"
 	"github.com/infinityCounter2/vh-trader/internal/models"
+	"github.com/infinityCounter2/vh-trader/internal/ratelimit"
 	"github.com/mailru/easyjson"
 )
 
 type Params struct {
 	Port int
+	// TLS serves HTTPS when set, plain HTTP otherwise.
+	TLS *TLSParams
+	// CORS allows browsers to call the server from other origins.
+	CORS CORSParams
+
+	// ReadTimeout, WriteTimeout and IdleTimeout are passed to the
+	// http.Server, ShutdownTimeout bounds the graceful shutdown
+	// and defaults to 10s.
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
+
+	// Limits bound the requests of clients.
+	Limits Limits
+
+	// Intervals are the candle intervals built, which must all be
+	// multiples of the finest one. Defaults to logic.BuilderIntervals.
+	Intervals []logic.BuilderInterval
+
+	// CacheLimit is the number of latest trades kept for
+	// each symbol, SymbolCacheLimits overrides it per symbol.
+	CacheLimit        int
+	SymbolCacheLimits map[string]int
+
+	// Symbols is the registry trades are validated against,
+	// when nil trades for any symbol are accepted.
+	Symbols *logic.SymbolRegistry
+	// Normalizer maps the symbols of ingested trades and queries
+	// to canonical symbols, when nil symbols are only upper cased.
+	Normalizer *logic.SymbolNormalizer
+
+	// LatePolicy and AllowedLateness are passed to every
+	// candle builder to handle trades for closed candles.
+	LatePolicy      logic.LatePolicy
+	AllowedLateness time.Duration
+
+	// Bars is keyed by symbol and lists the bar builders to run
+	// for it, the "*" key lists the ones run for every symbol.
+	Bars map[string][]logic.BarBuilderParams
+
+	// ShutdownDrain is how long the server keeps serving with
+	// /readyz failing once shutdown starts, so that load
+	// balancers stop routing to it before it stops listening.
+	ShutdownDrain time.Duration
+
+	// APIKeys authorizes requests, when nil every request is served.
+	APIKeys *auth.Keyring
+	// Signing verifies the signatures of batches ingested,
+	// when nil batches don't need to be signed.
+	Signing *auth.Verifier
+
+	// Reload loads the settings applied on Server.Reload,
+	// when nil the config can't be reloaded.
+	Reload func() (Reloadable, error)
+
+	// Logger is the logger of the server,
+	// when nil slog.Default() is used.
+	Logger *slog.Logger
+	// LogLevel is the level Logger logs at, set again by
+	// Server.Reload, when nil the level can't be reloaded.
+	LogLevel *slog.LevelVar
 }
 
 type Server struct {
-	p Params
+	p      Params
+	logger *slog.Logger
+	// intervals are sorted, the first being the finest.
+	intervals []logic.BuilderInterval
 
 	// The server handles deduping of trades
 	// from input itself but in a production system
 	// this should all be abstracted away.
-	knwnMtx       sync.Mutex
-	knownTradeIDs map[string]struct{}
+	knwnMtx sync.Mutex
+	// knownTrades is keyed by TradeID and contains the latest
+	// version of every trade ingested, including cancelled ones
+	// so that they cannot be ingested again.
+	knownTrades map[string]knownTrade
+
+	symbolLocksMtx sync.Mutex
+	// symbolLocks is keyed by symbol and held while trades of the symbol
+	// are applied, so that cancels and corrections of a trade are applied
+	// after it without serializing the ingestion of other symbols.
+	symbolLocks map[string]*sync.Mutex
 	// tradeStore will store all the trades ingested
 	// and also be called to respond to requests to get
 	// trades for a symbol
 	tradeStore *logic.TradeStore
+	// tradeHistory stores every trade ingested to serve
+	// time range queries for trades of a symbol.
+	tradeHistory *logic.TradeHistory
+
+	symbols    *logic.SymbolRegistry
+	normalizer *logic.SymbolNormalizer
 
 	builderMtx sync.RWMutex
+	// series is keyed by symbol and contains the candle
+	// series building the candles of every interval.
+	series map[string]*logic.CandleSeries
 	// builders is keyed "symbol_interval" and contains
-	// all the candle builders
+	// all the candle builders of the series, to be read from.
 	builders map[string]*logic.CandleBuilder
+
+	tickerMtx sync.RWMutex
+	// tickers is keyed by symbol and holds the ticker of the candles of
+	// the finest interval, updated whenever the candles change.
+	tickers map[string]*logic.TickerTracker
+
+	barMtx sync.RWMutex
+	// bars is keyed "symbol_type_size" and contains
+	// all the bar builders.
+	bars map[string]*logic.BarBuilder
+
+	// rebuildMtx is held for reading while trades are applied to the
+	// trade history and the candles, so that rebuilds can swap in
+	// candles consistent with the history.
+	rebuildMtx     sync.RWMutex
+	rebuildJobsMtx sync.RWMutex
+	// rebuildJobs is keyed by job ID.
+	rebuildJobs   map[string]*models.RebuildJob
+	rebuildJobSeq int
+
+	heikinAshiMtx sync.Mutex
+	// heikinAshi is keyed "symbol_interval" and holds the
+	// Heikin-Ashi view of the candles of each builder.
+	heikinAshi map[string]*logic.HeikinAshiTracker
+
+	metrics *serverMetrics
+
+	keyring  atomic.Pointer[auth.Keyring]
+	verifier atomic.Pointer[auth.Verifier]
+	// nonces are those of the signed batches ingested, kept
+	// across reloads so that batches can't be replayed.
+	nonces *auth.NonceCache
+
+	limits        atomic.Pointer[Limits]
+	corsParams    atomic.Pointer[CORSParams]
+	ingestLimiter *ratelimit.Limiter
+	readLimiter   *ratelimit.Limiter
+
+	reloadMtx sync.Mutex
+	// reloadable are the settings last applied, to diff reloads against.
+	reloadable Reloadable
+	// symbolsEdited is set once /admin/symbols edits the
+	// registry, as reloading the registry discards the edits.
+	symbolsEdited atomic.Bool
+
+	// draining is set once shutdown starts, failing readiness.
+	draining atomic.Bool
+
+	// indicators holds the indicator values computed
+	// so far for the series most recently requested.
+	indicators *indicatorCache
+}
+
+type knownTrade struct {
+	trade     models.Trade
+	cancelled bool
 }
 
 func NewServer(p Params) *Server {
+	symbols := p.Symbols
+	if symbols == nil {
+		symbols = logic.NewOpenSymbolRegistry()
+	}
+	normalizer := p.Normalizer
+	if normalizer == nil {
+		// An empty normalizer can't be invalid.
+		normalizer, _ = logic.NewSymbolNormalizer(models.SymbolNormalization{})
+	}
+
+	logger := p.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	intervals := slices.Clone(p.Intervals)
+	if len(intervals) == 0 {
+		intervals = slices.Clone(logic.BuilderIntervals)
+	}
+	slices.Sort(intervals)
+
 	// Standard HTTP Mux server, no need for anything fancy
-	return &Server{
-		p:             p,
-		knwnMtx:       sync.Mutex{},
-		knownTradeIDs: make(map[string]struct{}),
+	s := &Server{
+		p:         p,
+		logger:    logger,
+		intervals: intervals,
+		reloadable: Reloadable{
+			CacheLimit:        p.CacheLimit,
+			SymbolCacheLimits: p.SymbolCacheLimits,
+			APIKeys:           p.APIKeys,
+			Signing:           p.Signing,
+			Limits:            p.Limits,
+			CORS:              p.CORS,
+		},
+		knwnMtx:     sync.Mutex{},
+		knownTrades: make(map[string]knownTrade),
+		symbolLocks: make(map[string]*sync.Mutex),
 		tradeStore: logic.NewTradeStore(logic.TradeStoreParams{
-			CacheLimit: 50,
+			CacheLimit:        p.CacheLimit,
+			SymbolCacheLimits: p.SymbolCacheLimits,
 		}),
-		builders:   make(map[string]*logic.CandleBuilder),
-		builderMtx: sync.RWMutex{},
+		tradeHistory:  logic.NewTradeHistory(),
+		symbols:       symbols,
+		normalizer:    normalizer,
+		series:        make(map[string]*logic.CandleSeries),
+		builders:      make(map[string]*logic.CandleBuilder),
+		builderMtx:    sync.RWMutex{},
+		tickers:       make(map[string]*logic.TickerTracker),
+		bars:          make(map[string]*logic.BarBuilder),
+		rebuildJobs:   make(map[string]*models.RebuildJob),
+		heikinAshi:    make(map[string]*logic.HeikinAshiTracker),
+		indicators:    newIndicatorCache(maxIndicatorTrackers),
+		nonces:        auth.NewNonceCache(),
+		ingestLimiter: ratelimit.NewLimiter(float64(p.Limits.IngestRate), p.Limits.ingestBurst()),
+		readLimiter:   ratelimit.NewLimiter(float64(p.Limits.ReadRate), p.Limits.readBurst()),
 	}
+	s.metrics = newServerMetrics(s)
+	s.keyring.Store(p.APIKeys)
+	s.verifier.Store(p.Signing)
+	s.limits.Store(&p.Limits)
+	s.corsParams.Store(&p.CORS)
+	if p.LogLevel != nil {
+		s.reloadable.LogLevel = p.LogLevel.Level()
+	}
+
+	return s
 }
 
 // Run starts the HTTP server and will continue until either an
@@ -58,20 +259,31 @@ func (s *Server) Run(ctx context.Context) error {
 	// pushing to it can exit immediately.
 	errCh := make(chan error, 1)
 
-	mux := http.NewServeMux()
-
-	mux.HandleFunc("/ingest", s.ingestHandler)
-	mux.HandleFunc("/trades", s.tradesHandler)
-	mux.HandleFunc("/candles", s.candlesHandler)
-
 	srv := &http.Server{
-		Addr:    fmt.Sprintf(":%d", s.p.Port),
-		Handler: middleware(mux),
+		Addr:         fmt.Sprintf(":%d", s.p.Port),
+		Handler:      s.handler(),
+		ReadTimeout:  s.p.ReadTimeout,
+		WriteTimeout: s.p.WriteTimeout,
+		IdleTimeout:  s.p.IdleTimeout,
+	}
+
+	if s.p.TLS != nil {
+		certs, err := newCertReloader(*s.p.TLS, s.logger)
+		if err != nil {
+			return fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		srv.TLSConfig = certs.config()
+		go certs.watch(ctx)
 	}
 
 	// Start serving.
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		listen := srv.ListenAndServe
+		if srv.TLSConfig != nil {
+			// The certificate is served by the TLSConfig.
+			listen = func() error { return srv.ListenAndServeTLS("", "") }
+		}
+		if err := listen(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			errCh <- err // Abnormal termination event so push the error
 			return
 		}
@@ -81,8 +293,23 @@ func (s *Server) Run(ctx context.Context) error {
 	// Wait for the context to end
 	select {
 	case <-ctx.Done():
+		// Fail readiness and keep serving while draining.
+		s.draining.Store(true)
+		if s.p.ShutdownDrain > 0 {
+			s.logger.Info("Draining before shutdown", slog.Duration("drain", s.p.ShutdownDrain))
+			select {
+			case <-time.After(s.p.ShutdownDrain):
+			case err := <-errCh:
+				return err
+			}
+		}
+
 		// Attempt a graceful shutdown with a timeout
-		shCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		timeout := s.p.ShutdownTimeout
+		if timeout <= 0 {
+			timeout = 10 * time.Second
+		}
+		shCtx, cancel := context.WithTimeout(context.Background(), timeout)
 		defer cancel()
 
 		_ = srv.Shutdown(shCtx) // We wll drop the error here since it's inconsequential
@@ -95,6 +322,32 @@ func (s *Server) Run(ctx context.Context) error {
 	}
 }
 
+// handler returns the handler of every route, behind the middleware.
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/ingest", s.ingestHandler)
+	mux.HandleFunc("/trades", s.tradesHandler)
+	mux.HandleFunc("/trades/cancel", s.cancelTradeHandler)
+	mux.HandleFunc("/trades/correct", s.correctTradeHandler)
+	mux.HandleFunc("/candles", s.candlesHandler)
+	mux.HandleFunc("/indicators", s.indicatorsHandler)
+	mux.HandleFunc("/ticker", s.tickerHandler)
+	mux.HandleFunc("/tickers", s.tickersHandler)
+	mux.HandleFunc("/late_trades", s.lateTradesHandler)
+	mux.HandleFunc("/corrections", s.correctionsHandler)
+	mux.HandleFunc("/symbols", s.symbolsHandler)
+	mux.HandleFunc("/admin/symbols", s.adminSymbolsHandler)
+	mux.HandleFunc("/admin/rebuild", s.adminRebuildHandler)
+	mux.HandleFunc("/admin/reload", s.adminReloadHandler)
+	mux.Handle("/metrics", s.metrics.registry.Handler())
+	mux.HandleFunc("/healthz", s.healthzHandler)
+	mux.HandleFunc("/readyz", s.readyzHandler)
+	mux.HandleFunc("/version", s.versionHandler)
+
+	return s.middleware(mux)
+}
+
 // ingestHandler is a handler for the /ingest endpoint to ingest trades for processing
 //
 // Only handles POST requests
@@ -105,105 +358,470 @@ func (s *Server) ingestHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Load and parse JSON body
-	payload, err := io.ReadAll(r.Body)
-	if err != nil {
-		http.Error(w, "Failed to read POST body", http.StatusInternalServerError)
+	payload, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	// Verified before parsing so that unsigned
+	// batches cost as little as possible.
+	signed, ok := s.verifySignature(w, r, payload)
+	if !ok {
 		return
 	}
-
-	defer r.Body.Close()
 
 	var trades models.TradeList
 	if err := easyjson.Unmarshal(payload, &trades); err != nil {
 		http.Error(w, "Failed to parsed POST body to trades", http.StatusUnprocessableEntity)
 		return
 	}
+	if !s.takeIngestLimit(w, r, len(trades)) || !s.useNonce(w, r, signed) {
+		return
+	}
 
 	if len(trades) == 0 {
 		w.Write([]byte("Processed 0 trades!"))
 		return
 	}
 
-	dedupedTrades := make([]models.Trade, 0, len(trades))
-	tradesBySymbol := make(map[string][]models.Trade)
+	// Normalize before anything else so that trades are
+	// deduped and keyed by their canonical symbol.
+	for i := range trades {
+		trades[i].Symbol = s.normalizer.Normalize(trades[i].Symbol)
+	}
 
-	s.knwnMtx.Lock()
+	rejected := 0
+	rejectedBySymbol := make(map[string]int)
+	validTrades := make([]models.Trade, 0, len(trades))
+	validSymbols := make([]string, 0, 1)
 	for _, t := range trades {
-		if _, seen := s.knownTradeIDs[t.TradeID]; seen {
-			// Dedup trades
+		if err := s.symbols.ValidateTrade(t); err != nil {
+			// Rejected trades are not marked as known
+			// so they can be sent again once fixed.
+			rejected++
+			rejectedBySymbol[s.metricSymbol(t.Symbol)]++
 			continue
 		}
-		s.knownTradeIDs[t.TradeID] = struct{}{}
+		validTrades = append(validTrades, t)
+		validSymbols = append(validSymbols, t.Symbol)
+	}
+
+	// The symbols are locked until the trades are applied everywhere,
+	// so that a cancel or correction of them can only be applied after.
+	unlockSymbols := s.lockSymbols(validSymbols)
+
+	dedupedTrades := make([]models.Trade, 0, len(validTrades))
+	tradesBySymbol := make(map[string][]models.Trade)
+	duplicates := make(map[string]int)
+	s.knwnMtx.Lock()
+	for _, t := range validTrades {
+		if known, seen := s.knownTrades[t.TradeID]; seen {
+			// Dedup trades, counted for the symbol they were accepted for.
+			duplicates[known.trade.Symbol]++
+			continue
+		}
+		s.knownTrades[t.TradeID] = knownTrade{trade: t}
 		dedupedTrades = append(dedupedTrades, t)
 		tradesBySymbol[t.Symbol] = append(tradesBySymbol[t.Symbol], t)
 	}
 	s.knwnMtx.Unlock()
 
+	now := float64(time.Now().UnixMilli()) / 1000
+	for symbol, trades := range tradesBySymbol {
+		s.metrics.ingestedTrades.Add(float64(len(trades)), symbol, "accepted")
+		s.metrics.lastIngest.Set(now, symbol)
+	}
+	for symbol, n := range duplicates {
+		s.metrics.ingestedTrades.Add(float64(n), symbol, "duplicate")
+	}
+	for symbol, n := range rejectedBySymbol {
+		s.metrics.ingestedTrades.Add(float64(n), symbol, "rejected")
+	}
+
 	s.tradeStore.PushTrades(dedupedTrades)
 
+	s.rebuildMtx.RLock()
+	s.tradeHistory.PushTrades(dedupedTrades)
+
 	// On a symbol by symbol basis process the batch of trades
 	for symbol, trades := range tradesBySymbol {
-		for _, intvl := range logic.BuilderIntervals {
-			// For each interval, pass the trades to the builder
-			builderKey := getBuilderKey(symbol, intvl)
+		// The series rolls the trades up into every interval.
+		s.builderMtx.Lock()
+		series, ok := s.series[symbol]
+		if !ok {
+			// If no series exists for the symbol, initialize one
+			series = s.newCandleSeries()
+			s.series[symbol] = series
+			for _, intvl := range s.intervals {
+				s.builders[getBuilderKey(symbol, intvl)] = series.Builder(intvl)
+			}
+
+			s.tickerMtx.Lock()
+			s.tickers[symbol] = logic.NewTickerTracker(series.Builder(s.intervals[0]))
+			s.tickerMtx.Unlock()
+		}
+		s.builderMtx.Unlock()
 
-			s.builderMtx.Lock()
-			builder, ok := s.builders[builderKey]
+		series.ProcessTrades(trades)
+
+		for _, params := range s.symbolBarParams(symbol) {
+			barKey := getBarKey(symbol, params)
+
+			s.barMtx.Lock()
+			bars, ok := s.bars[barKey]
 			if !ok {
-				// If no builder exists for the symbol, initialize one
-				builder = logic.NewBuilder(logic.CandleBuilderParams{
-					Interval: intvl,
-				})
-				s.builders[builderKey] = builder
+				bars = logic.NewBarBuilder(params)
+				s.bars[barKey] = bars
 			}
-			s.builderMtx.Unlock()
+			s.barMtx.Unlock()
 
-			builder.ProcessTrades(trades)
+			bars.ProcessTrades(trades)
 		}
+
+		s.updateTicker(symbol, trades...)
 	}
+	s.rebuildMtx.RUnlock()
+	unlockSymbols()
+
+	s.requestLogger(r).Info("ingest",
+		slog.Int("bytes", len(payload)),
+		slog.Int("received", len(trades)),
+		slog.Int("accepted", len(dedupedTrades)),
+		slog.Int("duplicate", len(trades)-len(dedupedTrades)-rejected),
+		slog.Int("rejected", rejected),
+		slog.Int("symbols", len(tradesBySymbol)),
+	)
 
+	if rejected > 0 {
+		w.Write([]byte(fmt.Sprintf("Processs %d of %d trades, rejected %d!", len(dedupedTrades), len(trades), rejected)))
+		return
+	}
 	w.Write([]byte(fmt.Sprintf("Processs %d of %d trades!", len(dedupedTrades), len(trades))))
 }
 
-// tradesHandler is a handler for the /trades endpoint to server the 50 latest
-// trades on a GET request for any given "symbol".
+// tradesHandler is a handler for the /trades endpoint to server the latest cached
+// trades on a GET request for any given "symbol", optionally only the newest "limit".
+//
+// When any of the "from", "to", "cursor", "before" or "after" parameters are
+// given the trades are instead served from the trade history a page at a time.
 func (s *Server) tradesHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	symbol := getParam(r, "symbol")
+	symbol := s.getSymbolParam(r)
 	if symbol == "" {
 		http.Error(w, "symbol is required", http.StatusBadRequest)
 		return
 	}
 
-	trades := s.tradeStore.GetTrades(symbol)
+	for _, key := range historyParams {
+		if getParam(r, key) != "" {
+			s.tradeHistoryHandler(w, r, symbol)
+			return
+		}
+	}
+
+	limit, err := getLimitParam(r, s.tradeStore.Limit(symbol))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	trades := s.tradeStore.GetTrades(symbol, limit)
 	if trades == nil {
 		trades = make([]models.Trade, 0)
 	}
 
-	writeJSON(w, models.TradeList(trades))
+	s.writeJSON(w, r, models.TradeList(trades))
+}
+
+// historyParams are the parameters of /trades that query the trade history.
+var historyParams = []string{"from", "to", "cursor", "before", "after"}
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 1000
+)
+
+// tradeHistoryHandler serves a page of trades for the symbol from the trade history.
+//
+// The optional parameters are "from" and "to" timestamps(ms), "limit" (defaults to 50),
+// and one of "cursor" from a previous page or a "before" or "after" trade_id.
+// Cursors for the adjacent pages are returned in the X-Next-Cursor and X-Prev-Cursor headers.
+func (s *Server) tradeHistoryHandler(w http.ResponseWriter, r *http.Request, symbol string) {
+	q := logic.TradeQuery{Symbol: symbol}
+
+	var err error
+	if q.From, err = getInt64Param(r, "from"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if q.To, err = getInt64Param(r, "to"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limit, err := getLimitParam(r, maxHistoryLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q.Limit = limit
+	if q.Limit == 0 {
+		q.Limit = defaultHistoryLimit
+	}
+
+	if token := getParam(r, "cursor"); token != "" {
+		cursor, err := logic.ParseTradeCursor(token)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid cursor %q", token), http.StatusBadRequest)
+			return
+		}
+		if cursor.Before {
+			q.Before = &cursor
+		} else {
+			q.After = &cursor
+		}
+	}
+
+	for _, nav := range []struct {
+		key    string
+		cursor **logic.TradeCursor
+	}{{"before", &q.Before}, {"after", &q.After}} {
+		tradeID := getParam(r, nav.key)
+		if tradeID == "" {
+			continue
+		}
+		cursor, tradeSymbol, ok := s.tradeHistory.Cursor(tradeID)
+		if !ok {
+			http.Error(w, fmt.Sprintf("unknown trade %q", tradeID), http.StatusNotFound)
+			return
+		}
+		if tradeSymbol != symbol {
+			http.Error(w, fmt.Sprintf("trade %q is not a %s trade", tradeID, symbol), http.StatusBadRequest)
+			return
+		}
+		*nav.cursor = &cursor
+	}
+
+	page := s.tradeHistory.Query(q)
+	if page.Next != nil {
+		w.Header().Set("X-Next-Cursor", page.Next.Encode())
+	}
+	if page.Prev != nil {
+		w.Header().Set("X-Prev-Cursor", page.Prev.Encode())
+	}
+
+	s.writeJSON(w, r, models.TradeList(page.Trades))
+}
+
+// cancelTradeHandler is a handler for the /trades/cancel endpoint to bust a
+// previously ingested trade by its "trade_id", removing it from the trade cache
+// and recomputing the candles it contributed to.
+//
+// Only handles POST requests
+func (s *Server) cancelTradeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+
+	var cancel models.TradeCancel
+	if err := easyjson.Unmarshal(payload, &cancel); err != nil {
+		http.Error(w, "Failed to parsed POST body to trade cancel", http.StatusUnprocessableEntity)
+		return
+	}
+
+	if cancel.TradeID == "" {
+		http.Error(w, "trade_id is required", http.StatusBadRequest)
+		return
+	}
+	if !s.takeIngestLimit(w, r, 1) {
+		return
+	}
+
+	symbol, ok := s.knownSymbol(cancel.TradeID)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown trade %q", cancel.TradeID), http.StatusNotFound)
+		return
+	}
+	// The symbol is locked until the trade is removed everywhere so
+	// that concurrent amendments of a trade are applied in order.
+	defer s.lockSymbols([]string{symbol})()
+
+	s.knwnMtx.Lock()
+	known := s.knownTrades[cancel.TradeID]
+	if known.cancelled {
+		s.knwnMtx.Unlock()
+		http.Error(w, fmt.Sprintf("trade %q is already cancelled", cancel.TradeID), http.StatusConflict)
+		return
+	}
+	s.knownTrades[cancel.TradeID] = knownTrade{trade: known.trade, cancelled: true}
+	s.knwnMtx.Unlock()
+
+	s.tradeStore.RemoveTrade(known.trade.Symbol, known.trade.TradeID)
+	s.rebuildMtx.RLock()
+	s.tradeHistory.RemoveTrade(known.trade.TradeID)
+	if series := s.symbolSeries(known.trade.Symbol); series != nil && !series.CancelTrade(known.trade) {
+		s.recomputeCandles(series, known.trade.Symbol, known.trade.Timestamp)
+	}
+	s.rebuildMtx.RUnlock()
+	s.rebuildBars(known.trade.Symbol, known.trade.TradeID)
+	s.updateTicker(known.trade.Symbol, known.trade)
+
+	w.Write([]byte(fmt.Sprintf("Cancelled trade %s!", cancel.TradeID)))
+}
+
+// correctTradeHandler is a handler for the /trades/correct endpoint to amend a
+// previously ingested trade. The body is the amended trade, matched to the
+// original by its "trade_id", and the candles it affects are recomputed.
+//
+// Only handles POST requests
+func (s *Server) correctTradeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+
+	var amended models.Trade
+	if err := easyjson.Unmarshal(payload, &amended); err != nil {
+		http.Error(w, "Failed to parsed POST body to trade", http.StatusUnprocessableEntity)
+		return
+	}
+
+	if amended.TradeID == "" {
+		http.Error(w, "trade_id is required", http.StatusBadRequest)
+		return
+	}
+	if !s.takeIngestLimit(w, r, 1) {
+		return
+	}
+
+	symbol, ok := s.knownSymbol(amended.TradeID)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown trade %q", amended.TradeID), http.StatusNotFound)
+		return
+	}
+	if amended.Symbol == "" {
+		amended.Symbol = symbol
+	}
+	amended.Symbol = s.normalizer.Normalize(amended.Symbol)
+	if amended.Symbol != symbol {
+		// Moving a trade between symbols is a cancel and a new trade.
+		http.Error(w, "symbol of a trade cannot be corrected", http.StatusBadRequest)
+		return
+	}
+	defer s.lockSymbols([]string{symbol})()
+
+	s.knwnMtx.Lock()
+	known := s.knownTrades[amended.TradeID]
+	if known.cancelled {
+		s.knwnMtx.Unlock()
+		http.Error(w, fmt.Sprintf("trade %q is cancelled", amended.TradeID), http.StatusConflict)
+		return
+	}
+	s.knownTrades[amended.TradeID] = knownTrade{trade: amended}
+	s.knwnMtx.Unlock()
+
+	s.tradeStore.ReplaceTrade(amended)
+	s.rebuildMtx.RLock()
+	s.tradeHistory.ReplaceTrade(amended)
+	if series := s.symbolSeries(amended.Symbol); series != nil && !series.CorrectTrade(known.trade, amended) {
+		s.recomputeCandles(series, amended.Symbol, known.trade.Timestamp, amended.Timestamp)
+	}
+	s.rebuildMtx.RUnlock()
+	s.rebuildBars(amended.Symbol, amended.TradeID)
+	s.updateTicker(amended.Symbol, known.trade, amended)
+
+	w.Write([]byte(fmt.Sprintf("Corrected trade %s!", amended.TradeID)))
+}
+
+// knownSymbol returns the symbol of the known trade with the given
+// ID, which never changes, and false if the trade is unknown.
+func (s *Server) knownSymbol(tradeID string) (string, bool) {
+	s.knwnMtx.Lock()
+	defer s.knwnMtx.Unlock()
+
+	known, ok := s.knownTrades[tradeID]
+	return known.trade.Symbol, ok
+}
+
+// lockSymbols locks the given symbols, in sorted order so that
+// concurrent callers can't deadlock, and returns the func unlocking them.
+func (s *Server) lockSymbols(symbols []string) (unlock func()) {
+	symbols = slices.Clone(symbols)
+	slices.Sort(symbols)
+	symbols = slices.Compact(symbols)
+
+	locks := make([]*sync.Mutex, len(symbols))
+	s.symbolLocksMtx.Lock()
+	for i, symbol := range symbols {
+		lock, ok := s.symbolLocks[symbol]
+		if !ok {
+			lock = &sync.Mutex{}
+			s.symbolLocks[symbol] = lock
+		}
+		locks[i] = lock
+	}
+	s.symbolLocksMtx.Unlock()
+
+	for _, lock := range locks {
+		lock.Lock()
+	}
+	return func() {
+		for _, lock := range locks {
+			lock.Unlock()
+		}
+	}
 }
 
 // candlesHandler is a handler for the /candle endpoint to server aggregated
-// OHLC candle based on the required "symbol" and optional "interval" (defaults to 1m)
+// OHLC candle based on the required "symbol" and optional "interval" (defaults to the finest)
 // parameters.
+//
+// When the "type" parameter is given other than "time" the bars of that type
+// and "size" are served instead. Time candles can be served in the
+// Heikin-Ashi "style".
 func (s *Server) candlesHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	symbol := getParam(r, "symbol")
+	symbol := s.getSymbolParam(r)
 	if symbol == "" {
 		http.Error(w, "symbol is required", http.StatusBadRequest)
 		return
 	}
 
-	intvlArg := getParamOr(r, "interval", "1m")
-	intvl, ok := parseBuilderInterval(intvlArg)
+	style := getParam(r, "style")
+	if style != "" && style != styleHeikinAshi {
+		http.Error(w, fmt.Sprintf("invalid style value %q", style), http.StatusBadRequest)
+		return
+	}
+
+	if barType := getParamOr(r, "type", "time"); barType != "time" {
+		if style != "" {
+			http.Error(w, "style is only supported for time candles", http.StatusBadRequest)
+			return
+		}
+		s.barsHandler(w, r, symbol, barType)
+		return
+	}
+
+	intvlArg := getParamOr(r, "interval", s.defaultInterval())
+	intvl, ok := s.parseBuilderInterval(intvlArg)
 	if !ok {
 		http.Error(w,
 			fmt.Sprintf("invalid interval value %q", intvlArg),
@@ -218,22 +836,468 @@ func (s *Server) candlesHandler(w http.ResponseWriter, r *http.Request) {
 	s.builderMtx.RUnlock()
 
 	var candles models.CandleList
-	if builder != nil {
-		// There are no candles in this interval for this symbol
+	if builder != nil && style == styleHeikinAshi {
+		candles = s.heikinAshiTracker(builderKey).Candles(builder)
+		s.symbols.RoundCandles(symbol, candles)
+	} else if builder != nil {
 		candles = builder.GetCandles()
+		s.symbols.RoundCandles(symbol, candles)
 	} else {
+		// There are no candles in this interval for this symbol
 		candles = make(models.CandleList, 0)
 	}
 
-	writeJSON(w, candles)
+	s.writeJSON(w, r, candles)
+}
+
+const styleHeikinAshi = "heikin_ashi"
+
+// heikinAshiTracker returns the Heikin-Ashi tracker of the
+// builder with the given key, creating it if needed.
+func (s *Server) heikinAshiTracker(builderKey string) *logic.HeikinAshiTracker {
+	s.heikinAshiMtx.Lock()
+	defer s.heikinAshiMtx.Unlock()
+
+	tracker, ok := s.heikinAshi[builderKey]
+	if !ok {
+		tracker = logic.NewHeikinAshiTracker()
+		s.heikinAshi[builderKey] = tracker
+	}
+	return tracker
+}
+
+// barsHandler serves the bars of the given type and required "size"
+// parameter for the symbol, if bars of that size are configured for it.
+func (s *Server) barsHandler(w http.ResponseWriter, r *http.Request, symbol, barType string) {
+	typ, ok := logic.ParseBarType(barType)
+	if !ok {
+		http.Error(w, fmt.Sprintf("invalid type value %q", barType), http.StatusBadRequest)
+		return
+	}
+
+	sizeArg := getParam(r, "size")
+	if sizeArg == "" {
+		http.Error(w, "size is required", http.StatusBadRequest)
+		return
+	}
+	size, err := strconv.ParseFloat(sizeArg, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid size value %q", sizeArg), http.StatusBadRequest)
+		return
+	}
+
+	params := logic.BarBuilderParams{Type: typ, Size: size}
+	if !slices.Contains(s.symbolBarParams(symbol), params) {
+		http.Error(w,
+			fmt.Sprintf("%s bars of size %s are not configured for symbol %q", barType, sizeArg, symbol),
+			http.StatusNotFound,
+		)
+		return
+	}
+
+	s.barMtx.RLock()
+	bars := s.bars[getBarKey(symbol, params)]
+	s.barMtx.RUnlock()
+
+	var candles models.CandleList
+	if bars != nil {
+		candles = bars.GetCandles()
+		s.symbols.RoundCandles(symbol, candles)
+	} else {
+		// There are no trades for this symbol yet.
+		candles = make(models.CandleList, 0)
+	}
+
+	s.writeJSON(w, r, candles)
+}
+
+// indicatorsHandler is a handler for the /indicators endpoint to serve a
+// technical indicator over the candles of the required "symbol" parameter.
+//
+// The indicator is selected by the required "name" parameter and its
+// optional comma separated "params", e.g. name=macd&params=12,26,9.
+func (s *Server) indicatorsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	symbol := s.getSymbolParam(r)
+	if symbol == "" {
+		http.Error(w, "symbol is required", http.StatusBadRequest)
+		return
+	}
+
+	intvlArg := getParamOr(r, "interval", s.defaultInterval())
+	intvl, ok := s.parseBuilderInterval(intvlArg)
+	if !ok {
+		http.Error(w,
+			fmt.Sprintf("invalid interval value %q", intvlArg),
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	name := strings.ToLower(getParam(r, "name"))
+	if name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+
+	var params []float64
+	if arg := getParam(r, "params"); arg != "" {
+		for _, v := range strings.Split(arg, ",") {
+			param, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid params value %q", arg), http.StatusBadRequest)
+				return
+			}
+			params = append(params, param)
+		}
+	}
+
+	tracker, err := logic.NewIndicatorTracker(name, params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Reuse the tracker of the series so only the
+	// candles closed since the last request are computed.
+	trackerKey := fmt.Sprintf("%s_%s_%v", getBuilderKey(symbol, intvl), name, tracker.Params())
+	tracker = s.indicators.get(trackerKey, tracker)
+
+	s.builderMtx.RLock()
+	builder := s.builders[getBuilderKey(symbol, intvl)]
+	s.builderMtx.RUnlock()
+
+	series := models.IndicatorSeries{
+		Name:     name,
+		Symbol:   symbol,
+		Interval: formatBuilderInterval(intvl),
+		Params:   tracker.Params(),
+		Fields:   tracker.Fields(),
+		Points:   []models.IndicatorPoint{},
+	}
+	if builder != nil {
+		series.Points = tracker.Points(builder)
+	}
+
+	s.writeJSON(w, r, series)
+}
+
+// symbolsHandler is a handler for the /symbols endpoint to serve every symbol
+// that has been seen on a trade or is registered, with stats of its trades.
+func (s *Server) symbolsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	infos := make(map[string]*models.SymbolInfo)
+	for _, symbol := range s.tradeHistory.Symbols() {
+		stats, _ := s.tradeHistory.Stats(symbol)
+		infos[symbol] = &models.SymbolInfo{
+			Symbol:              symbol,
+			FirstTradeTimestamp: stats.FirstTradeTimestamp,
+			LastTradeTimestamp:  stats.LastTradeTimestamp,
+			TradeCount:          stats.TradeCount,
+			LastPrice:           stats.LastPrice,
+		}
+	}
+	for _, metadata := range s.symbols.List() {
+		info, ok := infos[metadata.Symbol]
+		if !ok {
+			info = &models.SymbolInfo{Symbol: metadata.Symbol}
+			infos[metadata.Symbol] = info
+		}
+		info.Metadata = &metadata
+	}
+
+	list := make(models.SymbolInfoList, 0, len(infos))
+	for symbol, info := range infos {
+		info.Intervals = make([]string, 0, len(s.intervals))
+		for _, intvl := range s.intervals {
+			s.builderMtx.RLock()
+			builder := s.builders[getBuilderKey(symbol, intvl)]
+			s.builderMtx.RUnlock()
+
+			if builder != nil && builder.HasCandles() {
+				info.Intervals = append(info.Intervals, formatBuilderInterval(intvl))
+			}
+		}
+		list = append(list, *info)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Symbol < list[j].Symbol
+	})
+
+	s.writeJSON(w, r, list)
+}
+
+// adminSymbolsHandler is a handler for the /admin/symbols endpoint to edit
+// the symbol registry.
+//
+// PUT requests add or replace the symbol in the body, DELETE requests
+// remove the symbol given by the "symbol" parameter.
+func (s *Server) adminSymbolsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPut:
+		payload, ok := readBody(w, r)
+		if !ok {
+			return
+		}
+
+		var symbol models.Symbol
+		if err := easyjson.Unmarshal(payload, &symbol); err != nil {
+			http.Error(w, "Failed to parsed PUT body to symbol", http.StatusUnprocessableEntity)
+			return
+		}
+		// Registered as the symbol of the trades it applies to.
+		if symbol.Symbol != "" {
+			symbol.Symbol = s.normalizer.Normalize(symbol.Symbol)
+		}
+
+		if err := s.symbols.Upsert(symbol); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.symbolsEdited.Store(true)
+
+		s.writeJSON(w, r, symbol)
+
+	case http.MethodDelete:
+		symbol := s.getSymbolParam(r)
+		if symbol == "" {
+			http.Error(w, "symbol is required", http.StatusBadRequest)
+			return
+		}
+
+		if !s.symbols.Delete(symbol) {
+			http.Error(w, fmt.Sprintf("unknown symbol %q", symbol), http.StatusNotFound)
+			return
+		}
+		s.symbolsEdited.Store(true)
+
+		w.Write([]byte(fmt.Sprintf("Deleted symbol %s!", symbol)))
+
+	default:
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// tickerHandler is a handler for the /ticker endpoint to serve
+// the 24 hour ticker of the required "symbol" parameter.
+func (s *Server) tickerHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	symbol := s.getSymbolParam(r)
+	if symbol == "" {
+		http.Error(w, "symbol is required", http.StatusBadRequest)
+		return
+	}
+
+	s.tickerMtx.RLock()
+	tracker := s.tickers[symbol]
+	s.tickerMtx.RUnlock()
+
+	var ticker models.Ticker
+	ok := false
+	if tracker != nil {
+		ticker, ok = tracker.Ticker(symbol, time.Now().UnixMilli())
+	}
+	if !ok {
+		http.Error(w, fmt.Sprintf("no ticker for symbol %q", symbol), http.StatusNotFound)
+		return
+	}
+
+	s.writeJSON(w, r, ticker)
+}
+
+// tickersHandler is a handler for the /tickers endpoint to
+// serve the 24 hour tickers of every symbol.
+func (s *Server) tickersHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	now := time.Now().UnixMilli()
+	s.tickerMtx.RLock()
+	tickers := make(models.TickerList, 0, len(s.tickers))
+	for symbol, tracker := range s.tickers {
+		if ticker, ok := tracker.Ticker(symbol, now); ok {
+			tickers = append(tickers, ticker)
+		}
+	}
+	s.tickerMtx.RUnlock()
+
+	sort.Slice(tickers, func(i, j int) bool {
+		return tickers[i].Symbol < tickers[j].Symbol
+	})
+
+	s.writeJSON(w, r, tickers)
+}
+
+// updateTicker updates the ticker of the symbol with the
+// candles of the trades processed, cancelled or corrected.
+func (s *Server) updateTicker(symbol string, trades ...models.Trade) {
+	s.tickerMtx.RLock()
+	tracker := s.tickers[symbol]
+	s.tickerMtx.RUnlock()
+
+	if tracker != nil {
+		tracker.Update(trades...)
+	}
+}
+
+// resetTicker rereads the candles of the ticker of the
+// symbol, after the candles of the symbol were replaced.
+func (s *Server) resetTicker(symbol string) {
+	s.tickerMtx.RLock()
+	tracker := s.tickers[symbol]
+	s.tickerMtx.RUnlock()
+
+	if tracker != nil {
+		tracker.Reset()
+	}
+}
+
+// lateTradesHandler is a handler for the /late_trades endpoint to serve the
+// late trade counters of every interval for the required "symbol" parameter.
+func (s *Server) lateTradesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	symbol := s.getSymbolParam(r)
+	if symbol == "" {
+		http.Error(w, "symbol is required", http.StatusBadRequest)
+		return
+	}
+
+	stats := make(models.LateTradeStatsList, 0, len(s.intervals))
+	for _, intvl := range s.intervals {
+		s.builderMtx.RLock()
+		builder := s.builders[getBuilderKey(symbol, intvl)]
+		s.builderMtx.RUnlock()
+
+		if builder == nil {
+			continue
+		}
+
+		late := builder.LateStats()
+		stats = append(stats, models.LateTradeStats{
+			Symbol:    symbol,
+			Interval:  formatBuilderInterval(intvl),
+			Late:      late.Late,
+			Dropped:   late.Dropped,
+			Corrected: late.Corrected,
+		})
+	}
+
+	s.writeJSON(w, r, stats)
+}
+
+// correctionsHandler is a handler for the /corrections endpoint to serve the
+// trades routed to the corrections log for the required "symbol" and optional
+// "interval" (defaults to the finest) parameters.
+func (s *Server) correctionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	symbol := s.getSymbolParam(r)
+	if symbol == "" {
+		http.Error(w, "symbol is required", http.StatusBadRequest)
+		return
+	}
+
+	intvlArg := getParamOr(r, "interval", s.defaultInterval())
+	intvl, ok := s.parseBuilderInterval(intvlArg)
+	if !ok {
+		http.Error(w,
+			fmt.Sprintf("invalid interval value %q", intvlArg),
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	s.builderMtx.RLock()
+	builder := s.builders[getBuilderKey(symbol, intvl)]
+	s.builderMtx.RUnlock()
+
+	trades := make(models.TradeList, 0)
+	if builder != nil {
+		trades = builder.Corrections()
+	}
+
+	s.writeJSON(w, r, trades)
+}
+
+// symbolBarParams returns the params of every bar builder to run for the symbol.
+func (s *Server) symbolBarParams(symbol string) []logic.BarBuilderParams {
+	params := append([]logic.BarBuilderParams{}, s.p.Bars[symbol]...)
+	for _, p := range s.p.Bars["*"] {
+		if !slices.Contains(params, p) {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// rebuildBars rebuilds the existing bar builders of the symbol from the
+// bar holding the trade on, replaying its trade history in the order it
+// was ingested, as bars can't be amended like candles since every bar
+// after an amended trade may change.
+func (s *Server) rebuildBars(symbol, tradeID string) {
+	seq, ok := s.tradeHistory.Seq(tradeID)
+	if !ok {
+		return
+	}
+
+	for _, params := range s.symbolBarParams(symbol) {
+		s.barMtx.RLock()
+		bars := s.bars[getBarKey(symbol, params)]
+		s.barMtx.RUnlock()
+
+		if bars == nil {
+			continue
+		}
+		bars.Rebuild(seq, func(seq int) ([]logic.IngestedTrade, int) {
+			return s.tradeHistory.IngestedSince(symbol, seq)
+		})
+	}
+}
+
+// newCandleSeries creates a candle series building every interval.
+func (s *Server) newCandleSeries() *logic.CandleSeries {
+	return logic.NewCandleSeries(logic.CandleBuilderParams{
+		LatePolicy:      s.p.LatePolicy,
+		AllowedLateness: s.p.AllowedLateness,
+	}, s.intervals)
+}
+
+// symbolSeries returns the candle series of the symbol, or nil if there is none.
+func (s *Server) symbolSeries(symbol string) *logic.CandleSeries {
+	s.builderMtx.RLock()
+	defer s.builderMtx.RUnlock()
+
+	return s.series[symbol]
 }
 
 // writeJSON is a helper for serializing the response via easyjson
 // and writing it back to the client.
-func writeJSON(w http.ResponseWriter, data easyjson.Marshaler) {
+func (s *Server) writeJSON(w http.ResponseWriter, r *http.Request, data easyjson.Marshaler) {
 	payload, err := easyjson.Marshal(data)
 	if err != nil {
-		fmt.Printf("Failed to marshal response: %s\n", err)
+		s.requestLogger(r).Error("Failed to marshal response", slog.Any("error", err))
 		http.Error(w, "Internal Error", http.StatusInternalServerError)
 		return
 	}
@@ -241,10 +1305,31 @@ func writeJSON(w http.ResponseWriter, data easyjson.Marshaler) {
 	w.Header().Set("Content-Type", "application/json")
 	_, err = w.Write(payload)
 	if err != nil {
-		fmt.Printf("Failed to write response to client: %s\n", err)
+		s.requestLogger(r).Warn("Failed to write response to client", slog.Any("error", err))
 	}
 }
 
+// readBody reads the body of the request, responding with an
+// error and returning false if it can't be read or is too large.
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	defer r.Body.Close()
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w,
+				fmt.Sprintf("%s body exceeds %d bytes", r.Method, maxBytesErr.Limit),
+				http.StatusRequestEntityTooLarge,
+			)
+			return nil, false
+		}
+		http.Error(w, fmt.Sprintf("Failed to read %s body", r.Method), http.StatusInternalServerError)
+		return nil, false
+	}
+	return payload, true
+}
+
 // getParam retrieves a query parameter from the request URL.
 // It returns the parameter's value as a string. If the parameter is not found,
 // an empty string is returned.
@@ -252,6 +1337,17 @@ func getParam(r *http.Request, key string) string {
 	return r.URL.Query().Get(key)
 }
 
+// getSymbolParam retrieves the "symbol" query parameter from the
+// request URL, normalized to its canonical symbol. If the parameter
+// is not found, an empty string is returned.
+func (s *Server) getSymbolParam(r *http.Request) string {
+	symbol := getParam(r, "symbol")
+	if symbol == "" {
+		return ""
+	}
+	return s.normalizer.Normalize(symbol)
+}
+
 // getParamOr retrieves a query parameter from the request URL.
 // It returns the parameter's value as a string. If the parameter is not found,
 // the provided defaultValue is returned instead.
@@ -263,28 +1359,113 @@ func getParamOr(r *http.Request, key, defVal string) string {
 	return val
 }
 
-// Simple request logging and validation middleware.
+// getInt64Param retrieves an integer query parameter from the request URL.
+// It returns 0 if the parameter is not found.
+func getInt64Param(r *http.Request, key string) (int64, error) {
+	val := getParam(r, key)
+	if val == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value %q", key, val)
+	}
+	return n, nil
+}
+
+// getLimitParam returns the value of the "limit" query parameter,
+// which must be between 1 and max when given, or 0 if not given.
+func getLimitParam(r *http.Request, maxLimit int) (int, error) {
+	if getParam(r, "limit") == "" {
+		return 0, nil
+	}
+	limit, err := getInt64Param(r, "limit")
+	if err != nil || limit < 1 || limit > int64(maxLimit) {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxLimit)
+	}
+	return int(limit), nil
+}
+
+// unmatchedRoute labels requests that match none of the routes.
+const unmatchedRoute = "unmatched"
+
+// Simple request logging and validation middleware, also
+// recording the latency of each request by its route.
+//
+// CORS preflights are answered first, see cors. Other requests are
+// authorized by their API key before being served, see authorize,
+// and read requests are rate limited per client.
+//
+// Every request is tagged with a request ID, propagated from the
+// X-Request-ID header when given, which is echoed in the response
+// and added to every log line of the request.
 //
 // This would be available as built in by some frameworks
 // such as GIN.
-func middleware(next http.Handler) http.Handler {
+func (s *Server) middleware(mux *http.ServeMux) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
-		next.ServeHTTP(w, r)
-		fmt.Printf("%s %s %s\n", r.Method, r.URL.Path, time.Since(start))
+
+		id := requestID(r)
+		w.Header().Set(requestIDHeader, id)
+		logger := s.logger.With(slog.String("request_id", id))
+
+		rec := &responseRecorder{ResponseWriter: w}
+		if limit := s.limits.Load().MaxBodyBytes; limit > 0 {
+			r.Body = http.MaxBytesReader(rec, r.Body, limit)
+		}
+
+		// Label by the route pattern rather than the path
+		// so unknown paths can't blow up the cardinality.
+		_, route := mux.Handler(r)
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		// Preflights are answered by cors, without credentials.
+		if preflight := s.cors(rec, r); !preflight {
+			if key, ok := s.authorize(rec, r, route); ok {
+				if key.Name != "" {
+					// Audits what every key did, like the trades it ingested.
+					logger = logger.With(slog.String("api_key", key.Name))
+				}
+				ctx := context.WithValue(r.Context(), loggerKey{}, logger)
+				ctx = context.WithValue(ctx, clientKey{}, rateLimitClient(r, key))
+				r = r.WithContext(ctx)
+
+				if routeRoles[route] != auth.RoleRead || s.takeRateLimit(rec, r, s.readLimiter, "read", 1) {
+					mux.ServeHTTP(rec, r)
+				}
+			}
+		}
+		elapsed := time.Since(start)
+
+		logRequest(logger, r, route, rec, elapsed)
+		s.metrics.requestDuration.Observe(elapsed.Seconds(), route, metricMethod(r))
 	})
 }
 
-var validIntervals = map[string]logic.BuilderInterval{
-	"1m":  logic.BuilderInterval1m,
-	"5m":  logic.BuilderInterval5m,
-	"15m": logic.BuilderInterval15m,
-	"1h":  logic.BuilderInterval1h,
+// parseBuilderInterval returns the interval of the query parameter,
+// and false unless candles are built at the interval.
+func (s *Server) parseBuilderInterval(k string) (logic.BuilderInterval, bool) {
+	intvl, ok := logic.ParseBuilderInterval(k)
+	return intvl, ok && slices.Contains(s.intervals, intvl)
+}
+
+// defaultInterval is the query parameter of the finest interval.
+func (s *Server) defaultInterval() string {
+	return formatBuilderInterval(s.intervals[0])
+}
+
+// formatBuilderInterval returns the query parameter
+// value of the interval.
+func formatBuilderInterval(intvl logic.BuilderInterval) string {
+	return logic.FormatBuilderInterval(intvl)
 }
 
-func parseBuilderInterval(k string) (logic.BuilderInterval, bool) {
-	intvl, ok := validIntervals[k]
-	return intvl, ok
+func getBarKey(symbol string, p logic.BarBuilderParams) string {
+	return fmt.Sprintf("%s_%s", symbol, p)
 }
 
 func getBuilderKey(symbol string, intvl logic.BuilderInterval) string {
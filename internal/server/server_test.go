@@ -0,0 +1,690 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/infinityCounter2/vh-trader/internal/auth"
+	"github.com/infinityCounter2/vh-trader/internal/logic"
+	"github.com/infinityCounter2/vh-trader/internal/models"
+	"github.com/mailru/easyjson"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	readKey   = "read-key"
+	ingestKey = "ingest-key"
+	adminKey  = "admin-key"
+
+	signingSecret = "0123456789abcdef0123456789abcdef"
+)
+
+func testKeyring(t *testing.T) *auth.Keyring {
+	keyring, err := auth.NewKeyring([]auth.Key{
+		{Name: "reader", Hash: auth.HashKey(readKey), Roles: []auth.Role{auth.RoleRead}},
+		{Name: "feed", Hash: auth.HashKey(ingestKey), Roles: []auth.Role{auth.RoleIngest}},
+		{Name: "ops", Hash: auth.HashKey(adminKey), Roles: []auth.Role{auth.RoleAdmin}},
+	})
+	require.NoError(t, err, "Failed to create keyring")
+	return keyring
+}
+
+func newTestServer(p Params) (*Server, http.Handler) {
+	p.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewServer(p)
+	return s, s.handler()
+}
+
+// serve sends the request to the handler, with the key as
+// a bearer token unless it is empty.
+func serve(h http.Handler, method, target, key, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if key != "" {
+		r.Header.Set("Authorization", "Bearer "+key)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// tradesBody returns the trades as the body of an ingest.
+func tradesBody(t *testing.T, trades ...models.Trade) string {
+	body, err := easyjson.Marshal(models.TradeList(trades))
+	require.NoError(t, err, "Failed to marshal trades")
+	return string(body)
+}
+
+// decode unmarshals the body of a response, which must be a 200.
+func decode(t *testing.T, w *httptest.ResponseRecorder, v easyjson.Unmarshaler) {
+	require.Equal(t, http.StatusOK, w.Code, "Unexpected status: %s", w.Body.String())
+	require.NoError(t, easyjson.Unmarshal(w.Body.Bytes(), v), "Failed to unmarshal response")
+}
+
+func TestMiddleware_Roles(t *testing.T) {
+	_, h := newTestServer(Params{APIKeys: testKeyring(t)})
+
+	routes := []struct {
+		role           auth.Role
+		method, target string
+		body           string
+	}{
+		{auth.RoleRead, http.MethodGet, "/tickers", ""},
+		{auth.RoleIngest, http.MethodPost, "/ingest", "[]"},
+		{auth.RoleAdmin, http.MethodGet, "/admin/rebuild", ""},
+	}
+	keys := []struct {
+		key  string
+		role auth.Role
+	}{
+		{readKey, auth.RoleRead},
+		{ingestKey, auth.RoleIngest},
+		{adminKey, auth.RoleAdmin},
+	}
+
+	for _, k := range keys {
+		for _, route := range routes {
+			w := serve(h, route.method, route.target, k.key, route.body)
+			if k.role == route.role || k.role == auth.RoleAdmin {
+				require.Equalf(t, http.StatusOK, w.Code, "Expected %s key to be served %s", k.role, route.target)
+			} else {
+				require.Equalf(t, http.StatusForbidden, w.Code, "Expected %s key to be forbidden %s", k.role, route.target)
+			}
+		}
+	}
+}
+
+func TestMiddleware_Authenticate(t *testing.T) {
+	_, h := newTestServer(Params{APIKeys: testKeyring(t)})
+
+	w := serve(h, http.MethodGet, "/tickers", "", "")
+	require.Equal(t, http.StatusUnauthorized, w.Code, "Expected a missing key to be unauthorized")
+	require.NotEmpty(t, w.Header().Get("WWW-Authenticate"), "Expected the auth scheme to be advertised")
+
+	w = serve(h, http.MethodGet, "/tickers", "wrong-key", "")
+	require.Equal(t, http.StatusUnauthorized, w.Code, "Expected a wrong key to be unauthorized")
+
+	r := httptest.NewRequest(http.MethodGet, "/tickers", nil)
+	r.Header.Set(apiKeyHeader, readKey)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code, "Expected the key to be accepted in the X-API-Key header")
+
+	w = serve(h, http.MethodGet, "/tickers", readKey, "")
+	require.Equal(t, http.StatusOK, w.Code, "Expected the key to be accepted as a bearer token")
+	require.NotEmpty(t, w.Header().Get(requestIDHeader), "Expected a request ID")
+
+	for _, route := range publicRoutes {
+		w = serve(h, http.MethodGet, route, "", "")
+		require.Equalf(t, http.StatusOK, w.Code, "Expected %s to be served without a key", route)
+	}
+
+	w = serve(h, http.MethodGet, "/unknown", "", "")
+	require.Equal(t, http.StatusNotFound, w.Code, "Expected unknown paths to be not found")
+	w = serve(h, http.MethodGet, "/admin/unknown", readKey, "")
+	require.Equal(t, http.StatusNotFound, w.Code, "Expected unknown paths to be not found")
+}
+
+func TestMiddleware_NoKeyring(t *testing.T) {
+	_, h := newTestServer(Params{})
+
+	for _, target := range []string{"/tickers", "/admin/rebuild"} {
+		w := serve(h, http.MethodGet, target, "", "")
+		require.Equalf(t, http.StatusOK, w.Code, "Expected %s to be served without a keyring", target)
+	}
+}
+
+func TestMiddleware_CORS(t *testing.T) {
+	_, h := newTestServer(Params{
+		APIKeys: testKeyring(t),
+		CORS: CORSParams{
+			AllowedOrigins: []string{"https://charts.example.com"},
+			AllowedMethods: []string{http.MethodGet},
+			AllowedHeaders: []string{"Authorization"},
+			ExposedHeaders: []string{"X-Next-Cursor"},
+			MaxAge:         10 * time.Minute,
+		},
+	})
+
+	preflight := func(origin, method string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodOptions, "/tickers", nil)
+		r.Header.Set("Origin", origin)
+		r.Header.Set("Access-Control-Request-Method", method)
+		r.Header.Set("Access-Control-Request-Headers", "authorization")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := preflight("https://charts.example.com", http.MethodGet)
+	require.Equal(t, http.StatusNoContent, w.Code, "Expected preflights to be answered without a key")
+	require.Equal(t, "https://charts.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "GET", w.Header().Get("Access-Control-Allow-Methods"))
+	require.Equal(t, "Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+	require.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+
+	w = preflight("https://evil.example.com", http.MethodGet)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), "Expected other origins to fail the preflight")
+
+	w = preflight("https://charts.example.com", http.MethodDelete)
+	require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), "Expected other methods to fail the preflight")
+
+	r := httptest.NewRequest(http.MethodGet, "/tickers", nil)
+	r.Header.Set("Origin", "https://charts.example.com")
+	r.Header.Set("Authorization", "Bearer "+readKey)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "https://charts.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "X-Next-Cursor", w.Header().Get("Access-Control-Expose-Headers"))
+
+	r = httptest.NewRequest(http.MethodGet, "/tickers", nil)
+	r.Header.Set("Origin", "https://charts.example.com")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusUnauthorized, w.Code, "Expected requests after the preflight to need a key")
+}
+
+func TestMiddleware_Limits(t *testing.T) {
+	_, h := newTestServer(Params{Limits: Limits{MaxBodyBytes: 64, MaxTradesPerBatch: 1}})
+
+	w := serve(h, http.MethodPost, "/ingest", "", "["+strings.Repeat(" ", 64)+"]")
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code, "Expected bodies over the limit to be too large")
+
+	w = serve(h, http.MethodPost, "/ingest", "", `[{"trade_id":"1"},{"trade_id":"2"}]`)
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code, "Expected batches over the limit to be too large")
+}
+
+func TestMiddleware_RateLimits(t *testing.T) {
+	_, h := newTestServer(Params{
+		APIKeys: testKeyring(t),
+		Limits:  Limits{IngestRate: 1, IngestBurst: 2, ReadRate: 1, ReadBurst: 2},
+	})
+
+	w := serve(h, http.MethodPost, "/ingest", ingestKey,
+		`[{"trade_id":"1","symbol":"BTC_USD","price":1,"size":1,"timestamp":1700000000000},`+
+			`{"trade_id":"2","symbol":"BTC_USD","price":1,"size":1,"timestamp":1700000000000}]`)
+	require.Equal(t, http.StatusOK, w.Code, "Expected a batch within the burst to be ingested")
+	w = serve(h, http.MethodPost, "/ingest", ingestKey,
+		`[{"trade_id":"3","symbol":"BTC_USD","price":1,"size":1,"timestamp":1700000000000}]`)
+	require.Equal(t, http.StatusTooManyRequests, w.Code, "Expected the ingest rate to be limited")
+	require.Equal(t, "1", w.Header().Get("Retry-After"), "Expected the seconds until a trade can be ingested")
+
+	w = serve(h, http.MethodPost, "/ingest", ingestKey, "[{},{},{}]")
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code, "Expected batches over the burst to be too large")
+
+	for i := 0; i < 2; i++ {
+		w = serve(h, http.MethodGet, "/tickers", readKey, "")
+		require.Equal(t, http.StatusOK, w.Code, "Expected reads within the burst to be served")
+	}
+	w = serve(h, http.MethodGet, "/tickers", readKey, "")
+	require.Equal(t, http.StatusTooManyRequests, w.Code, "Expected the read rate to be limited")
+	require.NotEmpty(t, w.Header().Get("Retry-After"), "Expected a Retry-After header")
+
+	w = serve(h, http.MethodGet, "/tickers", adminKey, "")
+	require.Equal(t, http.StatusOK, w.Code, "Expected rate limits per key")
+
+	// Failed auth is limited by IP.
+	for i := 0; i < 2; i++ {
+		w = serve(h, http.MethodGet, "/tickers", "wrong-key", "")
+		require.Equal(t, http.StatusUnauthorized, w.Code, "Expected a wrong key to be unauthorized")
+	}
+	w = serve(h, http.MethodGet, "/tickers", "wrong-key", "")
+	require.Equal(t, http.StatusTooManyRequests, w.Code, "Expected failed auth to be rate limited")
+}
+
+func TestMiddleware_SignedRetry(t *testing.T) {
+	verifier, err := auth.NewVerifier(map[string]string{"feed": signingSecret}, time.Minute)
+	require.NoError(t, err, "Failed to create verifier")
+	s, h := newTestServer(Params{Signing: verifier, Limits: Limits{MaxTradesPerBatch: 1}})
+
+	body := `[{"trade_id":"1","symbol":"BTC_USD","price":1,"size":1,"timestamp":1700000000000},` +
+		`{"trade_id":"2","symbol":"BTC_USD","price":1,"size":1,"timestamp":1700000000000}]`
+	signed := func() *httptest.ResponseRecorder {
+		ts := time.Now().UnixMilli()
+		r := httptest.NewRequest(http.MethodPost, "/ingest", strings.NewReader(body))
+		r.Header.Set(auth.SignatureHeader, auth.Sign([]byte(signingSecret), http.MethodPost, "/ingest", ts, "n1", []byte(body)))
+		r.Header.Set(auth.SignatureKeyHeader, "feed")
+		r.Header.Set(auth.SignatureTimestampHeader, strconv.FormatInt(ts, 10))
+		r.Header.Set(auth.SignatureNonceHeader, "n1")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, signed().Code, "Expected the batch to be too large")
+
+	s.setLimits(Limits{})
+	require.Equal(t, http.StatusOK, signed().Code, "Expected the rejected batch to be retried with its nonce")
+	require.Equal(t, http.StatusUnauthorized, signed().Code, "Expected the nonce to be used once ingested")
+}
+
+func TestMiddleware_Readyz(t *testing.T) {
+	s, h := newTestServer(Params{APIKeys: testKeyring(t)})
+
+	w := serve(h, http.MethodGet, "/readyz", "", "")
+	require.Equal(t, http.StatusOK, w.Code, "Expected the server to be ready")
+
+	s.draining.Store(true)
+	w = serve(h, http.MethodGet, "/readyz", "", "")
+	require.Equal(t, http.StatusServiceUnavailable, w.Code, "Expected the server not to be ready while draining")
+	w = serve(h, http.MethodGet, "/healthz", "", "")
+	require.Equal(t, http.StatusOK, w.Code, "Expected the server to stay healthy while draining")
+	w = serve(h, http.MethodGet, "/tickers", readKey, "")
+	require.Equal(t, http.StatusOK, w.Code, "Expected requests to be served while draining")
+}
+
+func TestCancelAndCorrect_PrunedCandles(t *testing.T) {
+	_, h := newTestServer(Params{AllowedLateness: 5 * time.Minute})
+
+	base := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	trades := []models.Trade{
+		{TradeID: "a", Symbol: "BTC_USD", Timestamp: base.Add(10 * time.Second).UnixMilli(), Price: 100, Size: 2},
+		{TradeID: "b", Symbol: "BTC_USD", Timestamp: base.Add(7 * time.Minute).UnixMilli(), Price: 110, Size: 1},
+		{TradeID: "c", Symbol: "BTC_USD", Timestamp: base.Add(8 * time.Minute).UnixMilli(), Price: 120, Size: 1},
+	}
+	w := serve(h, http.MethodPost, "/ingest", "", tradesBody(t, trades...))
+	require.Equal(t, http.StatusOK, w.Code, "Expected the trades to be ingested")
+
+	candles := func(interval string) models.CandleList {
+		var candles models.CandleList
+		decode(t, serve(h, http.MethodGet, "/candles?symbol=BTC_USD&interval="+interval, "", ""), &candles)
+		return candles
+	}
+
+	// The trades of the 10:01 candle were pruned, so it's rebuilt from the history.
+	moved := trades[1]
+	moved.Timestamp = base.Add(20 * time.Second).UnixMilli()
+	body, err := easyjson.Marshal(moved)
+	require.NoError(t, err, "Failed to marshal trade")
+	w = serve(h, http.MethodPost, "/trades/correct", "", string(body))
+	require.Equal(t, http.StatusOK, w.Code, "Expected the trade to be corrected")
+
+	minutes := candles("1m")
+	require.Len(t, minutes, 2, "Expected the candle of the moved trade to be removed")
+	require.Equal(t, base.Add(time.Minute).UnixMilli(), minutes[0].Timestamp)
+	require.Equal(t, 310.0, minutes[0].Volume, "Expected the moved trade in the 10:01 candle")
+	require.Equal(t, 110.0, minutes[0].Close, "Expected the moved trade in the 10:01 candle")
+	require.Equal(t, 120.0, minutes[1].Volume, "Expected the current candle to be kept")
+	require.Equal(t, 430.0, candles("1h")[0].Volume, "Expected the coarser candles to be kept")
+
+	w = serve(h, http.MethodPost, "/trades/cancel", "", `{"trade_id":"a"}`)
+	require.Equal(t, http.StatusOK, w.Code, "Expected the trade to be cancelled")
+
+	minutes = candles("1m")
+	require.Len(t, minutes, 2, "Expected the candles to be kept")
+	require.Equal(t, 110.0, minutes[0].Volume, "Expected the cancelled trade to be removed from its candle")
+	require.Equal(t, 230.0, candles("1h")[0].Volume, "Expected the cancelled trade to be removed from every interval")
+}
+
+func TestIngest_LocksOnlyItsSymbols(t *testing.T) {
+	s, h := newTestServer(Params{})
+
+	ingest := func(symbol string) <-chan int {
+		body := tradesBody(t, models.Trade{TradeID: symbol, Symbol: symbol, Timestamp: 1700000000000, Price: 1, Size: 1})
+		done := make(chan int, 1)
+		go func() {
+			done <- serve(h, http.MethodPost, "/ingest", "", body).Code
+		}()
+		return done
+	}
+
+	unlock := s.lockSymbols([]string{"BTC_USD"})
+	select {
+	case code := <-ingest("ETH_USD"):
+		require.Equal(t, http.StatusOK, code, "Expected the trade to be ingested")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected other symbols to be ingested while a symbol is locked")
+	}
+
+	done := ingest("BTC_USD")
+	select {
+	case <-done:
+		t.Fatal("Expected the locked symbol to wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	require.Equal(t, http.StatusOK, <-done, "Expected the trade to be ingested once unlocked")
+}
+
+func TestTickerHandler(t *testing.T) {
+	_, h := newTestServer(Params{})
+
+	w := serve(h, http.MethodGet, "/ticker?symbol=BTC_USD", "", "")
+	require.Equal(t, http.StatusNotFound, w.Code, "Expected no ticker before any trade")
+	w = serve(h, http.MethodGet, "/ticker", "", "")
+	require.Equal(t, http.StatusBadRequest, w.Code, "Expected the symbol to be required")
+
+	now := time.Now()
+	trades := []models.Trade{
+		{TradeID: "a", Symbol: "BTC_USD", Timestamp: now.Add(-2 * time.Hour).UnixMilli(), Price: 100, Size: 1},
+		{TradeID: "b", Symbol: "BTC_USD", Timestamp: now.Add(-time.Hour).UnixMilli(), Price: 130, Size: 1},
+		{TradeID: "c", Symbol: "BTC_USD", Timestamp: now.UnixMilli(), Price: 110, Size: 1},
+	}
+	w = serve(h, http.MethodPost, "/ingest", "", tradesBody(t, trades...))
+	require.Equal(t, http.StatusOK, w.Code, "Expected the trades to be ingested")
+
+	var ticker models.Ticker
+	decode(t, serve(h, http.MethodGet, "/ticker?symbol=btc_usd", "", ""), &ticker)
+	require.Equal(t, "BTC_USD", ticker.Symbol)
+	require.Equal(t, 110.0, ticker.LastPrice)
+	require.Equal(t, 100.0, ticker.Open)
+	require.Equal(t, 130.0, ticker.High)
+	require.Equal(t, 100.0, ticker.Low)
+	require.Equal(t, 340.0, ticker.Volume)
+
+	w = serve(h, http.MethodPost, "/trades/cancel", "", `{"trade_id":"b"}`)
+	require.Equal(t, http.StatusOK, w.Code, "Expected the trade to be cancelled")
+
+	var tickers models.TickerList
+	decode(t, serve(h, http.MethodGet, "/tickers", "", ""), &tickers)
+	require.Len(t, tickers, 1, "Expected a ticker per symbol")
+	require.Equal(t, 110.0, tickers[0].High, "Expected the cancelled trade to be removed from the ticker")
+	require.Equal(t, 210.0, tickers[0].Volume, "Expected the cancelled trade to be removed from the ticker")
+}
+
+func TestTradesHandler_Limit(t *testing.T) {
+	_, h := newTestServer(Params{CacheLimit: 3})
+
+	trades := make([]models.Trade, 0, 5)
+	for i := range 5 {
+		trades = append(trades, models.Trade{
+			TradeID:   strconv.Itoa(i),
+			Symbol:    "BTC_USD",
+			Price:     100,
+			Size:      1,
+			Timestamp: time.Date(2023, 1, 1, 0, 0, i, 0, time.UTC).UnixMilli(),
+		})
+	}
+	w := serve(h, http.MethodPost, "/ingest", "", tradesBody(t, trades...))
+	require.Equal(t, http.StatusOK, w.Code, "Failed to ingest: %s", w.Body.String())
+
+	var list models.TradeList
+	decode(t, serve(h, http.MethodGet, "/trades?symbol=BTC_USD", "", ""), &list)
+	require.Equal(t, models.TradeList(trades[2:]), list, "Expected every cached trade without a limit")
+	decode(t, serve(h, http.MethodGet, "/trades?symbol=BTC_USD&limit=1", "", ""), &list)
+	require.Equal(t, models.TradeList(trades[4:]), list, "Expected the newest trades up to the limit")
+
+	for _, limit := range []string{"0", "-1", "4", "many"} {
+		w = serve(h, http.MethodGet, "/trades?symbol=BTC_USD&limit="+limit, "", "")
+		require.Equal(t, http.StatusBadRequest, w.Code, "Expected limit %s to be rejected", limit)
+		require.Equal(t, "limit must be between 1 and 3\n", w.Body.String())
+
+		w = serve(h, http.MethodGet, "/trades?symbol=BTC_USD&from=0&limit="+limit, "", "")
+		if limit == "4" {
+			require.Equal(t, http.StatusOK, w.Code, "Expected history pages over the cache limit")
+			continue
+		}
+		require.Equal(t, http.StatusBadRequest, w.Code, "Expected history limit %s to be rejected", limit)
+		require.Equal(t, "limit must be between 1 and 1000\n", w.Body.String())
+	}
+}
+
+func TestTradeHistoryHandler(t *testing.T) {
+	_, h := newTestServer(Params{})
+
+	base := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	trades := make([]models.Trade, 0, 10)
+	for i := range 10 {
+		trades = append(trades, models.Trade{
+			TradeID:   strconv.Itoa(i),
+			Symbol:    "BTC_USD",
+			Price:     100,
+			Size:      1,
+			Timestamp: base.Add(time.Duration(i) * time.Second).UnixMilli(),
+		})
+	}
+	other := models.Trade{TradeID: "eth", Symbol: "ETH_USD", Price: 10, Size: 1, Timestamp: base.UnixMilli()}
+	w := serve(h, http.MethodPost, "/ingest", "", tradesBody(t, append(trades, other)...))
+	require.Equal(t, http.StatusOK, w.Code, "Failed to ingest: %s", w.Body.String())
+
+	page := func(query string) (models.TradeList, *httptest.ResponseRecorder) {
+		t.Helper()
+		w := serve(h, http.MethodGet, "/trades?symbol=BTC_USD&"+query, "", "")
+		var list models.TradeList
+		decode(t, w, &list)
+		return list, w
+	}
+	from := "from=" + strconv.FormatInt(trades[0].Timestamp, 10)
+
+	list, w := page(from + "&limit=4")
+	require.Equal(t, models.TradeList(trades[:4]), list, "Expected the oldest page")
+	require.Empty(t, w.Header().Get("X-Prev-Cursor"), "Expected no cursor before the first trade")
+	next := w.Header().Get("X-Next-Cursor")
+	require.NotEmpty(t, next, "Expected a cursor to the next page")
+
+	// Trades ingested meanwhile don't shift the pages.
+	late := models.Trade{TradeID: "late", Symbol: "BTC_USD", Price: 100, Size: 1, Timestamp: trades[1].Timestamp - 1}
+	w = serve(h, http.MethodPost, "/ingest", "", tradesBody(t, late))
+	require.Equal(t, http.StatusOK, w.Code, "Failed to ingest: %s", w.Body.String())
+
+	list, w = page("cursor=" + next + "&limit=4")
+	require.Equal(t, models.TradeList(trades[4:8]), list, "Expected the page after the cursor")
+	prev := w.Header().Get("X-Prev-Cursor")
+	require.NotEmpty(t, prev, "Expected a cursor to the previous page")
+	list, w = page("cursor=" + w.Header().Get("X-Next-Cursor") + "&limit=4")
+	require.Equal(t, models.TradeList(trades[8:]), list, "Expected the last page")
+	require.Empty(t, w.Header().Get("X-Next-Cursor"), "Expected no cursor after the last trade")
+
+	list, _ = page("cursor=" + prev + "&limit=3")
+	require.Equal(t, models.TradeList{trades[1], trades[2], trades[3]}, list, "Expected the page before the cursor")
+
+	list, _ = page("from=" + strconv.FormatInt(trades[2].Timestamp, 10) + "&to=" + strconv.FormatInt(trades[5].Timestamp, 10))
+	require.Equal(t, models.TradeList(trades[2:5]), list, "Expected the trades within from and to")
+	list, _ = page("before=5&limit=2")
+	require.Equal(t, models.TradeList(trades[3:5]), list, "Expected the trades before the trade")
+	list, _ = page("after=5&limit=2")
+	require.Equal(t, models.TradeList(trades[6:8]), list, "Expected the trades after the trade")
+
+	for _, tt := range []struct {
+		query  string
+		status int
+		msg    string
+	}{
+		{"cursor=bogus", http.StatusBadRequest, `invalid cursor "bogus"`},
+		{"from=yesterday", http.StatusBadRequest, `invalid from value "yesterday"`},
+		{"to=later", http.StatusBadRequest, `invalid to value "later"`},
+		{"before=missing", http.StatusNotFound, `unknown trade "missing"`},
+		{"after=eth", http.StatusBadRequest, `trade "eth" is not a BTC_USD trade`},
+		{from + "&limit=1001", http.StatusBadRequest, "limit must be between 1 and 1000"},
+	} {
+		w = serve(h, http.MethodGet, "/trades?symbol=BTC_USD&"+tt.query, "", "")
+		require.Equal(t, tt.status, w.Code, "Unexpected status of %s", tt.query)
+		require.Equal(t, tt.msg+"\n", w.Body.String(), "Unexpected error of %s", tt.query)
+	}
+}
+
+func TestSymbolsHandler(t *testing.T) {
+	registry, err := logic.NewSymbolRegistry([]models.Symbol{
+		{Symbol: "BTC_USD", Status: models.SymbolStatusTrading},
+		{Symbol: "SOL_USD", Status: models.SymbolStatusHalted},
+	})
+	require.NoError(t, err, "Failed to create registry")
+	_, h := newTestServer(Params{Symbols: registry, Intervals: []logic.BuilderInterval{time.Minute, time.Hour}})
+
+	var list models.SymbolInfoList
+	decode(t, serve(h, http.MethodGet, "/symbols", "", ""), &list)
+	require.Equal(t, models.SymbolInfoList{
+		{Symbol: "BTC_USD", Intervals: []string{}, Metadata: &models.Symbol{Symbol: "BTC_USD", Status: models.SymbolStatusTrading}},
+		{Symbol: "SOL_USD", Intervals: []string{}, Metadata: &models.Symbol{Symbol: "SOL_USD", Status: models.SymbolStatusHalted}},
+	}, list, "Expected the registered symbols without stats before any trade")
+
+	base := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	trades := []models.Trade{
+		{TradeID: "1", Symbol: "BTC_USD", Price: 100, Size: 1, Timestamp: base.UnixMilli()},
+		{TradeID: "2", Symbol: "BTC_USD", Price: 110, Size: 1, Timestamp: base.Add(time.Minute).UnixMilli()},
+	}
+	w := serve(h, http.MethodPost, "/ingest", "", tradesBody(t, trades...))
+	require.Equal(t, http.StatusOK, w.Code, "Failed to ingest: %s", w.Body.String())
+
+	decode(t, serve(h, http.MethodGet, "/symbols", "", ""), &list)
+	require.Len(t, list, 2, "Expected a symbol per registered or traded symbol")
+	require.Equal(t, models.SymbolInfo{
+		Symbol:              "BTC_USD",
+		FirstTradeTimestamp: trades[0].Timestamp,
+		LastTradeTimestamp:  trades[1].Timestamp,
+		TradeCount:          2,
+		LastPrice:           110,
+		Intervals:           []string{"1m", "1h"},
+		Metadata:            &models.Symbol{Symbol: "BTC_USD", Status: models.SymbolStatusTrading},
+	}, list[0], "Expected the stats of the traded symbol")
+	require.Equal(t, "SOL_USD", list[1].Symbol)
+	require.Zero(t, list[1].TradeCount, "Expected no trades of the halted symbol")
+
+	w = serve(h, http.MethodPost, "/symbols", "", "")
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code, "Expected only GET")
+}
+
+func TestAdminRebuildHandler(t *testing.T) {
+	_, h := newTestServer(Params{
+		Intervals:  []logic.BuilderInterval{time.Minute, time.Hour},
+		LatePolicy: logic.LatePolicyReject,
+	})
+
+	base := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) string { return strconv.FormatInt(base.Add(d).UnixMilli(), 10) }
+	w := serve(h, http.MethodPost, "/ingest", "", tradesBody(t,
+		models.Trade{TradeID: "1", Symbol: "BTC_USD", Price: 100, Size: 1, Timestamp: base.Add(10 * time.Second).UnixMilli()},
+		models.Trade{TradeID: "2", Symbol: "BTC_USD", Price: 110, Size: 1, Timestamp: base.Add(2 * time.Minute).UnixMilli()},
+	))
+	require.Equal(t, http.StatusOK, w.Code, "Failed to ingest: %s", w.Body.String())
+	// Dropped from the candles as late, but kept in the history.
+	w = serve(h, http.MethodPost, "/ingest", "", tradesBody(t,
+		models.Trade{TradeID: "3", Symbol: "BTC_USD", Price: 90, Size: 1, Timestamp: base.Add(30 * time.Second).UnixMilli()},
+	))
+	require.Equal(t, http.StatusOK, w.Code, "Failed to ingest: %s", w.Body.String())
+
+	candles := func(interval string) models.CandleList {
+		var candles models.CandleList
+		decode(t, serve(h, http.MethodGet, "/candles?symbol=BTC_USD&interval="+interval, "", ""), &candles)
+		return candles
+	}
+	require.Equal(t, 100.0, candles("1m")[0].Volume, "Expected the late trade to be dropped")
+
+	w = serve(h, http.MethodPost, "/admin/rebuild?symbol=btc_usd&interval=1m&from="+at(20*time.Second)+"&to="+at(90*time.Second), "", "")
+	require.Equal(t, http.StatusAccepted, w.Code, "Expected the rebuild to start: %s", w.Body.String())
+	var job models.RebuildJob
+	require.NoError(t, easyjson.Unmarshal(w.Body.Bytes(), &job), "Failed to unmarshal job")
+	require.Equal(t, "BTC_USD", job.Symbol)
+	require.Equal(t, "1m", job.Interval)
+	require.Equal(t, base.UnixMilli(), job.From, "Expected from to be aligned to the interval")
+	require.Equal(t, base.Add(2*time.Minute).UnixMilli(), job.To, "Expected to to be aligned to the interval")
+
+	require.Eventually(t, func() bool {
+		decode(t, serve(h, http.MethodGet, "/admin/rebuild?id="+job.ID, "", ""), &job)
+		return job.Status == models.RebuildStatusDone
+	}, 5*time.Second, 10*time.Millisecond, "Expected the rebuild to finish")
+	require.Equal(t, int64(2), job.TradesTotal, "Expected the trades within the range to be replayed")
+	require.Equal(t, job.TradesTotal, job.TradesProcessed)
+
+	minutes := candles("1m")
+	require.Equal(t, 190.0, minutes[0].Volume, "Expected the late trade in the rebuilt candle")
+	require.Equal(t, 90.0, minutes[0].Low, "Expected the late trade in the rebuilt candle")
+	require.Equal(t, 210.0, candles("1h")[0].Volume, "Expected the other intervals to be kept")
+
+	var jobs models.RebuildJobList
+	decode(t, serve(h, http.MethodGet, "/admin/rebuild", "", ""), &jobs)
+	require.Len(t, jobs, 1, "Expected every job to be listed")
+	require.Equal(t, job, jobs[0])
+
+	for _, tt := range []struct {
+		method string
+		query  string
+		status int
+		msg    string
+	}{
+		{http.MethodPost, "", http.StatusBadRequest, "symbol is required"},
+		{http.MethodPost, "symbol=ETH_USD", http.StatusNotFound, `no candles for symbol "ETH_USD"`},
+		{http.MethodPost, "symbol=BTC_USD&interval=7m", http.StatusBadRequest, `invalid interval value "7m"`},
+		{http.MethodPost, "symbol=BTC_USD&from=soon", http.StatusBadRequest, `invalid from value "soon"`},
+		{http.MethodPost, "symbol=BTC_USD&from=" + at(time.Minute) + "&to=" + at(0), http.StatusBadRequest, "from must be before to"},
+		{http.MethodGet, "id=rebuild-9", http.StatusNotFound, `unknown rebuild job "rebuild-9"`},
+		{http.MethodDelete, "", http.StatusMethodNotAllowed, "Method Not Allowed"},
+	} {
+		w = serve(h, tt.method, "/admin/rebuild?"+tt.query, "", "")
+		require.Equal(t, tt.status, w.Code, "Unexpected status of %s %s", tt.method, tt.query)
+		require.Equal(t, tt.msg+"\n", w.Body.String(), "Unexpected error of %s %s", tt.method, tt.query)
+	}
+}
+
+func TestIndicatorsHandler(t *testing.T) {
+	_, h := newTestServer(Params{Intervals: []logic.BuilderInterval{time.Minute, time.Hour}})
+
+	base := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	ingest := func(id string, minute int, price float64) {
+		t.Helper()
+		w := serve(h, http.MethodPost, "/ingest", "", tradesBody(t, models.Trade{
+			TradeID:   id,
+			Symbol:    "BTC_USD",
+			Price:     price,
+			Size:      1,
+			Timestamp: base.Add(time.Duration(minute) * time.Minute).UnixMilli(),
+		}))
+		require.Equal(t, http.StatusOK, w.Code, "Failed to ingest: %s", w.Body.String())
+	}
+	closeAt := func(minute int) int64 { return base.Add(time.Duration(minute+1) * time.Minute).UnixMilli() }
+
+	var series models.IndicatorSeries
+	decode(t, serve(h, http.MethodGet, "/indicators?symbol=BTC_USD&name=sma&params=2", "", ""), &series)
+	require.Equal(t, models.IndicatorSeries{
+		Name:     "sma",
+		Symbol:   "BTC_USD",
+		Interval: "1m",
+		Params:   []float64{2},
+		Fields:   []string{"sma"},
+		Points:   []models.IndicatorPoint{},
+	}, series, "Expected no points without candles")
+
+	for i, price := range []float64{1, 2, 3, 4} {
+		ingest(strconv.Itoa(i), i, price)
+	}
+	decode(t, serve(h, http.MethodGet, "/indicators?symbol=btc_usd&name=SMA&params=2", "", ""), &series)
+	require.Equal(t, []models.IndicatorPoint{
+		{Timestamp: closeAt(1), Values: []float64{1.5}},
+		{Timestamp: closeAt(2), Values: []float64{2.5}},
+		{Timestamp: closeAt(3), Values: []float64{3.5}, Live: true},
+	}, series.Points, "Expected a point per candle after the warm up, the current one live")
+
+	// The current candle closes as the next one opens.
+	ingest("4", 4, 6)
+	decode(t, serve(h, http.MethodGet, "/indicators?symbol=BTC_USD&interval=1m&name=sma&params=2", "", ""), &series)
+	require.Equal(t, []models.IndicatorPoint{
+		{Timestamp: closeAt(1), Values: []float64{1.5}},
+		{Timestamp: closeAt(2), Values: []float64{2.5}},
+		{Timestamp: closeAt(3), Values: []float64{3.5}},
+		{Timestamp: closeAt(4), Values: []float64{5}, Live: true},
+	}, series.Points, "Expected the closed candle to be added to the points")
+
+	decode(t, serve(h, http.MethodGet, "/indicators?symbol=BTC_USD&interval=1h&name=macd", "", ""), &series)
+	require.Equal(t, []float64{12, 26, 9}, series.Params, "Expected the default params")
+	require.Equal(t, []string{"macd", "signal", "histogram"}, series.Fields)
+	require.Empty(t, series.Points, "Expected no points within the warm up")
+
+	for _, tt := range []struct {
+		query  string
+		status int
+		msg    string
+	}{
+		{"name=sma", http.StatusBadRequest, "symbol is required"},
+		{"symbol=BTC_USD", http.StatusBadRequest, "name is required"},
+		{"symbol=BTC_USD&name=sma&interval=7m", http.StatusBadRequest, `invalid interval value "7m"`},
+		{"symbol=BTC_USD&name=vwap", http.StatusBadRequest, `unknown indicator "vwap"`},
+		{"symbol=BTC_USD&name=sma&params=two", http.StatusBadRequest, `invalid params value "two"`},
+		{"symbol=BTC_USD&name=sma&params=2,3", http.StatusBadRequest, "sma takes at most 1 params"},
+		{"symbol=BTC_USD&name=sma&params=1.5", http.StatusBadRequest, "invalid sma param 1.5"},
+		{"symbol=BTC_USD&name=sma&params=1001", http.StatusBadRequest, "sma periods cannot exceed 1000"},
+	} {
+		w := serve(h, http.MethodGet, "/indicators?"+tt.query, "", "")
+		require.Equal(t, tt.status, w.Code, "Unexpected status of %s", tt.query)
+		require.Equal(t, tt.msg+"\n", w.Body.String(), "Unexpected error of %s", tt.query)
+	}
+
+	w := serve(h, http.MethodPost, "/indicators?symbol=BTC_USD&name=sma", "", "")
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code, "Expected only GET")
+}
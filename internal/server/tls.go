@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/infinityCounter2/vh-trader/internal/auth"
+)
+
+// certPollInterval is how often the TLS files are checked for changes.
+const certPollInterval = 10 * time.Second
+
+type TLSParams struct {
+	// CertFile and KeyFile are the PEM certificate and key served.
+	CertFile string
+	KeyFile  string
+
+	// MinVersion defaults to TLS 1.2, and CipherSuites
+	// to the secure suites of crypto/tls.
+	MinVersion   uint16
+	CipherSuites []uint16
+
+	// ClientCerts is the policy for client certificates,
+	// verified against the PEM certificates of ClientCAFile.
+	ClientCerts  auth.ClientCerts
+	ClientCAFile string
+}
+
+// certReloader holds the TLS config of the server, loading the
+// certificate, key and client CAs again whenever their files change.
+// Handshakes use the config current at the time, so connections
+// already established are kept.
+type certReloader struct {
+	p      TLSParams
+	logger *slog.Logger
+
+	current atomic.Pointer[tls.Config]
+	// modTimes are those of the files when last loaded.
+	modTimes []time.Time
+}
+
+// newCertReloader loads the TLS files, returning an error if any
+// of them are invalid.
+func newCertReloader(p TLSParams, logger *slog.Logger) (*certReloader, error) {
+	c := &certReloader{p: p, logger: logger}
+	c.modTimes = c.stat()
+
+	cfg, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	c.current.Store(cfg)
+	return c, nil
+}
+
+// files are the paths of the TLS files loaded.
+func (c *certReloader) files() []string {
+	files := []string{c.p.CertFile, c.p.KeyFile}
+	if c.p.ClientCAFile != "" {
+		files = append(files, c.p.ClientCAFile)
+	}
+	return files
+}
+
+// stat returns the modification times of the files,
+// the zero time for those that can't be read.
+func (c *certReloader) stat() []time.Time {
+	files := c.files()
+	modTimes := make([]time.Time, len(files))
+	for i, path := range files {
+		if info, err := os.Stat(path); err == nil {
+			modTimes[i] = info.ModTime()
+		}
+	}
+	return modTimes
+}
+
+func (c *certReloader) load() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(c.p.CertFile, c.p.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	minVersion := c.p.MinVersion
+	if minVersion == 0 {
+		minVersion = tls.VersionTLS12
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   minVersion,
+		CipherSuites: c.p.CipherSuites,
+		ClientAuth:   c.p.ClientCerts.TLSClientAuth(),
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+
+	if c.p.ClientCAFile != "" {
+		pem, err := os.ReadFile(c.p.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientCAs = x509.NewCertPool()
+		if !cfg.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificates found in the client CA file")
+		}
+	}
+	return cfg, nil
+}
+
+// config returns the TLS config of the http.Server,
+// which hands each handshake the current config.
+func (c *certReloader) config() *tls.Config {
+	return &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return c.current.Load(), nil
+		},
+	}
+}
+
+// watch reloads the TLS files every certPollInterval until the context
+// is done.
+func (c *certReloader) watch(ctx context.Context) {
+	ticker := time.NewTicker(certPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.reload()
+		}
+	}
+}
+
+// reload loads the TLS files again if they changed. Invalid files are
+// logged and the previous ones kept, as certificates are often
+// replaced one file at a time.
+func (c *certReloader) reload() {
+	modTimes := c.stat()
+	changed := false
+	for i := range modTimes {
+		changed = changed || !modTimes[i].Equal(c.modTimes[i])
+	}
+	if !changed {
+		return
+	}
+
+	cfg, err := c.load()
+	if err != nil {
+		c.logger.Warn("Failed to reload TLS certificate", slog.Any("error", err))
+		return
+	}
+	c.modTimes = modTimes
+	c.current.Store(cfg)
+
+	leaf := cfg.Certificates[0].Leaf
+	c.logger.Info("Reloaded TLS certificate",
+		slog.String("subject", leaf.Subject.String()),
+		slog.Time("not_after", leaf.NotAfter),
+	)
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/infinityCounter2/vh-trader/internal/auth"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCert writes a self-signed certificate for the common name and its
+// key to the files, bumping their modification time to at.
+func writeCert(t *testing.T, certFile, keyFile, commonName string, at time.Time) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err, "Failed to generate key")
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(at.UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             at.Add(-time.Hour),
+		NotAfter:              at.Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err, "Failed to create certificate")
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err, "Failed to parse certificate")
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err, "Failed to marshal key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	require.NoError(t, os.Chtimes(certFile, at, at))
+	require.NoError(t, os.Chtimes(keyFile, at, at))
+	return cert
+}
+
+// handshake connects to the reloader, returning
+// the common name of the certificate served.
+func handshake(t *testing.T, c *certReloader) string {
+	t.Helper()
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tls.Server(serverConn, c.config()).Handshake()
+	}()
+
+	client := tls.Client(clientConn, &tls.Config{InsecureSkipVerify: true})
+	require.NoError(t, client.Handshake(), "Failed handshake")
+	require.NoError(t, <-done, "Failed server handshake")
+	return client.ConnectionState().PeerCertificates[0].Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	p := TLSParams{CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "key.pem")}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	_, err := newCertReloader(p, logger)
+	require.Error(t, err, "Expected missing files to fail")
+
+	start := time.Now()
+	writeCert(t, p.CertFile, p.KeyFile, "first", start)
+	c, err := newCertReloader(p, logger)
+	require.NoError(t, err, "Failed to load certificate")
+	require.Equal(t, "first", handshake(t, c), "Expected the certificate loaded")
+	require.Equal(t, uint16(tls.VersionTLS12), c.current.Load().MinVersion, "Expected TLS 1.2 by default")
+
+	c.reload()
+	require.Equal(t, "first", handshake(t, c), "Expected unchanged files to be kept")
+
+	writeCert(t, p.CertFile, p.KeyFile, "second", start.Add(time.Minute))
+	c.reload()
+	require.Equal(t, "second", handshake(t, c), "Expected the changed certificate on the next handshake")
+
+	// Only the certificate replaced so far, its key not matching.
+	writeCert(t, p.CertFile, filepath.Join(dir, "next-key.pem"), "third", start.Add(2*time.Minute))
+	c.reload()
+	require.Equal(t, "second", handshake(t, c), "Expected the previous certificate kept when the reload fails")
+
+	require.NoError(t, os.Rename(filepath.Join(dir, "next-key.pem"), p.KeyFile))
+	require.NoError(t, os.Chtimes(p.KeyFile, start.Add(3*time.Minute), start.Add(3*time.Minute)))
+	c.reload()
+	require.Equal(t, "third", handshake(t, c), "Expected the certificate once its key is replaced too")
+}
+
+func TestCertReloader_ClientCAs(t *testing.T) {
+	dir := t.TempDir()
+	p := TLSParams{
+		CertFile:     filepath.Join(dir, "cert.pem"),
+		KeyFile:      filepath.Join(dir, "key.pem"),
+		ClientCerts:  auth.ClientCertsIngest,
+		ClientCAFile: filepath.Join(dir, "ca.pem"),
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	writeCert(t, p.CertFile, p.KeyFile, "server", time.Now())
+
+	require.NoError(t, os.WriteFile(p.ClientCAFile, []byte("not a certificate"), 0o600))
+	_, err := newCertReloader(p, logger)
+	require.ErrorContains(t, err, "no certificates", "Expected an invalid client CA file to fail")
+
+	ca := writeCert(t, p.ClientCAFile, filepath.Join(dir, "ca-key.pem"), "ca", time.Now())
+	c, err := newCertReloader(p, logger)
+	require.NoError(t, err, "Failed to load certificate")
+	cfg := c.current.Load()
+	require.Equal(t, tls.VerifyClientCertIfGiven, cfg.ClientAuth, "Expected client certificates to be optional for ingest")
+	_, err = ca.Verify(x509.VerifyOptions{Roots: cfg.ClientCAs, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	require.NoError(t, err, "Expected the client CA to be trusted")
+}
+
+func TestMiddleware_ClientCerts(t *testing.T) {
+	_, h := newTestServer(Params{TLS: &TLSParams{ClientCerts: auth.ClientCertsIngest}})
+
+	send := func(method, target, body string, verified bool) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		if verified {
+			r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := send(http.MethodPost, "/ingest", "[]", false)
+	require.Equal(t, http.StatusUnauthorized, w.Code, "Expected ingest to require a client certificate")
+	require.Contains(t, w.Body.String(), "requires a client certificate")
+
+	w = send(http.MethodPost, "/trades/cancel", "{}", false)
+	require.Equal(t, http.StatusUnauthorized, w.Code, "Expected cancels to require a client certificate")
+
+	w = send(http.MethodPost, "/ingest", "[]", true)
+	require.Equal(t, http.StatusOK, w.Code, "Expected ingest with a verified client certificate: %s", w.Body.String())
+
+	w = send(http.MethodGet, "/trades?symbol=BTC_USD", "", false)
+	require.Equal(t, http.StatusOK, w.Code, "Expected reads not to require a client certificate")
+}